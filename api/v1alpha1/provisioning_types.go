@@ -19,6 +19,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
@@ -51,6 +53,136 @@ const (
 	BootIsoSourceHttp  BootIsoSource = "http"
 )
 
+// LogLevel is the verbosity of the Ironic services
+// +kubebuilder:validation:Enum=error;info;debug
+type LogLevel string
+
+// LogLevel values
+const (
+	LogLevelError LogLevel = "error"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelDebug LogLevel = "debug"
+)
+
+// LogFormat is the format of the Ironic service logs
+// +kubebuilder:validation:Enum=text;json
+type LogFormat string
+
+// LogFormat values
+const (
+	LogFormatText LogFormat = "text"
+	LogFormatJSON LogFormat = "json"
+)
+
+// NetworkInterface is the Ironic network interface used for provisioning ports
+// +kubebuilder:validation:Enum=flat;neutron;noop
+type NetworkInterface string
+
+// NetworkInterface values
+const (
+	NetworkInterfaceFlat    NetworkInterface = "flat"
+	NetworkInterfaceNeutron NetworkInterface = "neutron"
+	NetworkInterfaceNoop    NetworkInterface = "noop"
+)
+
+// RPCAuthStrategy is the authentication of the JSON-RPC calls from the Ironic
+// API to the conductor
+// +kubebuilder:validation:Enum=noauth;http_basic
+type RPCAuthStrategy string
+
+// RPCAuthStrategy values
+const (
+	RPCAuthStrategyNoAuth    RPCAuthStrategy = "noauth"
+	RPCAuthStrategyHTTPBasic RPCAuthStrategy = "http_basic"
+)
+
+// SharedVolumeMedium is the storage medium backing the shared volume of the
+// metal3 pod
+// +kubebuilder:validation:Enum=Disk;Memory
+type SharedVolumeMedium string
+
+// SharedVolumeMedium values
+const (
+	SharedVolumeMediumDisk   SharedVolumeMedium = "Disk"
+	SharedVolumeMediumMemory SharedVolumeMedium = "Memory"
+)
+
+// DeploymentStrategy is the update strategy of the metal3 deployment
+// +kubebuilder:validation:Enum=Recreate;RollingUpdate
+type DeploymentStrategy string
+
+// DeploymentStrategy values
+const (
+	DeploymentStrategyRecreate      DeploymentStrategy = "Recreate"
+	DeploymentStrategyRollingUpdate DeploymentStrategy = "RollingUpdate"
+)
+
+// ProvisioningIPSource is where the metal3 pod takes the address Ironic binds
+// to from with a Disabled provisioning network
+// +kubebuilder:validation:Enum=HostIP;Interface
+type ProvisioningIPSource string
+
+// ProvisioningIPSource values
+const (
+	ProvisioningIPSourceHostIP    ProvisioningIPSource = "HostIP"
+	ProvisioningIPSourceInterface ProvisioningIPSource = "Interface"
+)
+
+// ProbeTuning overrides the timing of the probes of the metal3 containers.
+// Fields left unset keep the default of each probe.
+type ProbeTuning struct {
+	// InitialDelaySeconds is the delay before the first probe
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds is the interval between two probes, at least 1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds is the timeout of a single probe, at least 1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures before the
+	// probe fails, at least 1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// HostPathVolume mounts a host directory read-only into metal3 containers
+type HostPathVolume struct {
+	// Name identifies the volume, a DNS-1123 label of at most 58 characters
+	Name string `json:"name"`
+
+	// HostPath is the directory on the host, within /var/lib/metal3,
+	// /var/lib/tftpboot or /var/log/journal
+	HostPath string `json:"hostPath"`
+
+	// MountPath is the absolute path of the directory in the containers. It
+	// must not be /shared (or the SharedVolumePath), /auth, /certs,
+	// /etc/pki/ca-trust/extracted/pem or /var/run/secrets, within them or
+	// above them.
+	MountPath string `json:"mountPath"`
+
+	// Containers lists the metal3 containers the directory is mounted into
+	Containers []string `json:"containers"`
+}
+
+// DownloadProxy is the proxy used by the containers downloading the machine OS
+// images instead of the cluster proxy
+type DownloadProxy struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+}
+
+// EnvVarList is a list of container environment variables
+type EnvVarList []corev1.EnvVar
+
+// BMCVendors lists the BMC vendors that accept a polling override
+var BMCVendors = []string{"idrac", "ilo", "irmc", "redfish", "ipmi"}
+
+// CleanStepInterfaces lists the Ironic hardware interfaces exposing clean steps
+var CleanStepInterfaces = []string{"deploy", "power", "management", "bios", "raid"}
+
 // PreProvisioningOSDownloadURLs defines a set of URLs that the cluster
 // can use to provision RHCOS Live images
 type PreProvisioningOSDownloadURLs struct {
@@ -74,6 +206,11 @@ type ProvisioningSpec struct {
 	// have values like eth1 or ens3.
 	ProvisioningInterface string `json:"provisioningInterface,omitempty"`
 
+	// ProvisioningInterfaceMTU is the MTU set on the provisioning
+	// interface, e.g. 9000 for jumbo-frame provisioning networks. Must be
+	// between 576 and 9000. When not set, the interface MTU is unchanged.
+	ProvisioningInterfaceMTU int32 `json:"provisioningInterfaceMTU,omitempty"`
+
 	// ProvisioningMacAddresses is a list of mac addresses of network interfaces
 	// on a baremetal server to the provisioning network.
 	// Use this instead of ProvisioningInterface to allow interfaces of different
@@ -155,6 +292,12 @@ type ProvisioningSpec struct {
 	// configuration would be used for baremetal hosts across all namespaces.
 	WatchAllNamespaces bool `json:"watchAllNamespaces,omitempty"`
 
+	// WatchNamespaces restricts the baremetal hosts handled by this
+	// provisioning configuration to the listed namespaces, and takes
+	// precedence over WatchAllNamespaces. The list should include
+	// openshift-machine-api, which holds the hosts of the cluster itself.
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
 	// BootIsoSource provides a way to set the location where the iso image
 	// to boot the nodes will be served from.
 	// By default the boot iso image is cached locally and served from
@@ -183,6 +326,374 @@ type ProvisioningSpec struct {
 	// DisableVirtualMediaTLS turns off TLS on the virtual media server,
 	// which may be required for hardware that cannot accept HTTPS links.
 	DisableVirtualMediaTLS bool `json:"disableVirtualMediaTLS,omitempty"`
+
+	// HTTPPort is the host port httpd serves the images and the virtual
+	// media on over HTTP, for nodes where the default is already in use.
+	// It must not be one of the other ports bound on the masters.
+	// Defaults to 6180.
+	HTTPPort *int32 `json:"httpPort,omitempty"`
+
+	// IronicPort is the host port the Ironic API is exposed on, for nodes
+	// where the default is already in use. Defaults to 6385.
+	IronicPort *int32 `json:"ironicPort,omitempty"`
+
+	// InspectorPort is the host port the Ironic Inspector API is exposed
+	// on, for nodes where the default is already in use. Defaults to 5050.
+	InspectorPort *int32 `json:"inspectorPort,omitempty"`
+
+	// Paused scales the metal3 deployment down to zero, e.g. during node
+	// maintenance, without tearing down the other metal3 resources as
+	// deleting the Provisioning CR would. The single metal3 pod returns
+	// when it is set back to false. Defaults to false.
+	Paused bool `json:"paused,omitempty"`
+
+	// DeploymentStrategy is the update strategy of the metal3 deployment,
+	// either Recreate or RollingUpdate. RollingUpdate starts the new pod
+	// on another master before stopping the old one, so that for a while
+	// both answer DHCP and hold the provisioning IP. It is ignored on
+	// single-node clusters, where no other node has the host ports free.
+	// Defaults to Recreate.
+	DeploymentStrategy DeploymentStrategy `json:"deploymentStrategy,omitempty"`
+
+	// NodeSelector overrides the node selector of the metal3 pod. When
+	// neither NodeSelector nor Affinity is set, the pod is scheduled on
+	// the masters. Since the metal3 pod uses host networking and host
+	// ports, only one metal3 pod can run on any given node.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Affinity sets the scheduling constraints of the metal3 pod, for
+	// example to steer it to dedicated infrastructure nodes. The host
+	// networking and host port constraints described for NodeSelector
+	// still apply.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// HostPID makes the metal3 pod share the PID namespace of the host.
+	// WARNING: this is meant for debugging only, e.g. to run diagnostic
+	// tooling for PXE or network issues, and must not be left enabled on
+	// production clusters. Defaults to false.
+	HostPID bool `json:"hostPID,omitempty"`
+
+	// DisableSeccompProfile stops setting the RuntimeDefault seccomp
+	// profile on the metal3 pod, for clusters applying a custom profile
+	// instead. Privileged containers are never confined by the pod
+	// profile. Defaults to false.
+	DisableSeccompProfile bool `json:"disableSeccompProfile,omitempty"`
+
+	// BMCPollingOverrides sets the interval at which the Ironic conductor
+	// polls the BMCs of a given vendor, for hardware that cannot tolerate
+	// the default polling rate. Keys are BMC vendors (idrac, ilo, irmc,
+	// redfish or ipmi) and values are durations such as "90s" or "5m".
+	BMCPollingOverrides map[string]string `json:"bmcPollingOverrides,omitempty"`
+
+	// AdditionalTolerations are appended to the tolerations of the metal3
+	// pod, e.g. to let it run on masters carrying custom taints. Entries
+	// matching one of the built-in tolerations are ignored.
+	AdditionalTolerations []corev1.Toleration `json:"additionalTolerations,omitempty"`
+
+	// LogLevel sets the verbosity of the Ironic and Ironic Inspector
+	// services to one of error, info or debug. When not set, the services
+	// keep the log level of the Ironic image.
+	LogLevel LogLevel `json:"logLevel,omitempty"`
+
+	// LogFormat sets the format of the Ironic and Ironic Inspector logs to
+	// text or json, the latter being easier to parse for log aggregation
+	// pipelines. Defaults to text.
+	LogFormat LogFormat `json:"logFormat,omitempty"`
+
+	// EnableDebugEndpoints exposes pprof and development logging on the
+	// baremetal-operator for live troubleshooting. The debug endpoint is
+	// only bound to localhost inside the pod. Defaults to false.
+	EnableDebugEndpoints bool `json:"enableDebugEndpoints,omitempty"`
+
+	// NetworkInterface sets the default Ironic network interface of the
+	// provisioning ports to one of flat, neutron or noop. When not set,
+	// the default of the Ironic image is used.
+	NetworkInterface NetworkInterface `json:"networkInterface,omitempty"`
+
+	// ImagePullPolicy overrides the pull policy of every container of the
+	// metal3 deployment, e.g. Always during development when the same tag
+	// is pushed repeatedly. One of Always, IfNotPresent or Never. Defaults
+	// to IfNotPresent.
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// RequireImageDigests makes the operator refuse to deploy any metal3
+	// image that is not pinned by a sha256 digest, e.g. to guarantee that
+	// disconnected environments never run drifting tags. The offending
+	// images are reported in the status of this resource. Defaults to
+	// false.
+	RequireImageDigests bool `json:"requireImageDigests,omitempty"`
+
+	// EnableRetirementWorkflow enables the automated cleaning of Ironic by
+	// default, which retired nodes go through when they are torn down
+	// before leaving service. Defaults to false.
+	EnableRetirementWorkflow bool `json:"enableRetirementWorkflow,omitempty"`
+
+	// RetirementCleanSteps are the clean steps of the automated cleaning,
+	// run in the given order, each in the interface.step format, e.g.
+	// deploy.erase_devices_metadata. They apply to the automated cleaning
+	// of every node, retired or not. Only valid together with
+	// EnableRetirementWorkflow. When not set, the default clean steps of
+	// the Ironic image are used.
+	RetirementCleanSteps []string `json:"retirementCleanSteps,omitempty"`
+
+	// ServiceAccountTokenAudience is the audience of a bound service
+	// account token projected into the Ironic container, for Ironic to
+	// authenticate to the API. When not set, no token is projected.
+	ServiceAccountTokenAudience string `json:"serviceAccountTokenAudience,omitempty"`
+
+	// ImageDownloadTimeout is how long the conductor waits on the web
+	// server while downloading a deploy image, e.g. 5m for large images
+	// over slow links. When not set, the default of the Ironic image is
+	// used.
+	ImageDownloadTimeout *metav1.Duration `json:"imageDownloadTimeout,omitempty"`
+
+	// DeployTimeout is how long the conductor waits for the ramdisk to
+	// call back during a deployment before failing it, e.g. 2h for hosts
+	// with slow BMCs. Must be between 10m and 24h. When not set, the
+	// default of the Ironic image is used.
+	DeployTimeout *metav1.Duration `json:"deployTimeout,omitempty"`
+
+	// PowerStateSyncInterval is the interval at which the conductor checks
+	// the power state of the hosts against their BMC, e.g. 5m to spare slow
+	// IPMI BMCs. Must be between 30s and 1h. When not set, the default of
+	// the Ironic image is used.
+	PowerStateSyncInterval *metav1.Duration `json:"powerStateSyncInterval,omitempty"`
+
+	// InspectionTimeout is how long Ironic Inspector waits for a host to
+	// boot the ramdisk and report its inventory before failing the
+	// inspection, e.g. 90m for slow-booting hardware. Must be between 5m
+	// and 2h. When not set, the default of the Ironic image is used.
+	InspectionTimeout *metav1.Duration `json:"inspectionTimeout,omitempty"`
+
+	// DisableHostPorts removes the hostPort bindings of the host networked
+	// metal3, image cache and ironic proxy pods, for clusters whose
+	// admission policies reject hostPort. The services stay reachable on
+	// the same ports through host networking. Defaults to false.
+	DisableHostPorts bool `json:"disableHostPorts,omitempty"`
+
+	// HardenSecurityContext sets allowPrivilegeEscalation to false on the
+	// unprivileged metal3 and baremetal-operator containers. Privileged
+	// containers are left alone. Defaults to false.
+	HardenSecurityContext bool `json:"hardenSecurityContext,omitempty"`
+
+	// DHCPLeaseTime is the duration of the leases handed out by dnsmasq
+	// in Managed mode, e.g. 30m to recycle addresses faster on large
+	// fleets. Must be at least 2m. When not set, the dnsmasq default of
+	// 1h is used.
+	DHCPLeaseTime *metav1.Duration `json:"dhcpLeaseTime,omitempty"`
+
+	// TFTPBlockSize caps the TFTP block size served by dnsmasq, for PXE
+	// firmware failing with larger blocks. Must be between 8 and 65464
+	// bytes. When not set, the block size is negotiated with the client.
+	TFTPBlockSize *int32 `json:"tftpBlockSize,omitempty"`
+
+	// EnableSoftwareRAIDRoot makes Ironic use the agent RAID interface by
+	// default, so that nodes can be deployed on a software RAID root
+	// device. The RAID level is set per host, in the softwareRAIDVolumes
+	// of the BareMetalHost RAID configuration. Defaults to false.
+	EnableSoftwareRAIDRoot bool `json:"enableSoftwareRAIDRoot,omitempty"`
+
+	// InitContainerEnv adds environment variables to the init containers
+	// of the metal3 pod, keyed by init container name, e.g. download
+	// retries for metal3-machine-os-downloader. Variables already set by
+	// the operator are ignored.
+	InitContainerEnv map[string]EnvVarList `json:"initContainerEnv,omitempty"`
+
+	// ExtraBaremetalOperatorEnv adds environment variables to the
+	// baremetal-operator container, for tuning settings not exposed by
+	// this resource, e.g. BMO_CONCURRENCY. The variables set by the
+	// operator cannot be overridden.
+	ExtraBaremetalOperatorEnv []corev1.EnvVar `json:"extraBaremetalOperatorEnv,omitempty"`
+
+	// ExtraHostPathVolumes mounts host directories read-only into the
+	// metal3 containers, e.g. for debugging provisioning failures with
+	// host-level artifacts. Only /var/lib/metal3, /var/lib/tftpboot and
+	// /var/log/journal, or directories within them, may be mounted, and not
+	// where the metal3 containers mount their own volumes.
+	ExtraHostPathVolumes []HostPathVolume `json:"extraHostPathVolumes,omitempty"`
+
+	// ConductorGroup is the conductor group the Ironic conductor joins, used
+	// to shard nodes across conductors. It may only contain letters,
+	// digits, dashes, underscores and dots. When not set, the default of the
+	// Ironic image is used.
+	ConductorGroup *string `json:"conductorGroup,omitempty"`
+
+	// ConductorConcurrency is the maximum number of deployments the Ironic
+	// conductor runs concurrently, e.g. to raise it on large fleets. Must be
+	// between 1 and 100. When not set, the Ironic default is used.
+	ConductorConcurrency int32 `json:"conductorConcurrency,omitempty"`
+
+	// AdditionalNoProxy lists hosts, domains and CIDRs added to the NO_PROXY
+	// variable of the metal3 containers on top of the cluster proxy
+	// settings, e.g. for a registry mirror on the provisioning network.
+	AdditionalNoProxy []string `json:"additionalNoProxy,omitempty"`
+
+	// DownloadProxy overrides the HTTP_PROXY and HTTPS_PROXY of the
+	// machine OS downloader init containers, e.g. with a caching proxy for
+	// the large image fetches. The other containers, and NO_PROXY, keep
+	// the cluster proxy settings.
+	DownloadProxy *DownloadProxy `json:"downloadProxy,omitempty"`
+
+	// SharedVolumeMedium selects what backs the volume the metal3 pod serves
+	// images from. Memory uses a tmpfs, which speeds up image serving but
+	// counts against the memory of the pod, so it requires
+	// SharedVolumeSizeLimit. When not set, the node disk is used.
+	SharedVolumeMedium SharedVolumeMedium `json:"sharedVolumeMedium,omitempty"`
+
+	// SharedVolumeSizeLimit is the size limit of the shared volume of the
+	// metal3 pod. When not set, the volume is unbounded.
+	SharedVolumeSizeLimit *resource.Quantity `json:"sharedVolumeSizeLimit,omitempty"`
+
+	// AdditionalTrustBundleConfigMap is the name of a ConfigMap in the
+	// operator namespace whose ca-bundle.crt key holds extra trusted CAs,
+	// e.g. of a private registry mirroring the RHCOS images. The bundle is
+	// added to the trust directory next to the cluster trusted CA bundle.
+	// A missing ConfigMap is ignored.
+	AdditionalTrustBundleConfigMap string `json:"additionalTrustBundleConfigMap,omitempty"`
+
+	// ExternalTLSSecret is the name of a Secret in the operator namespace
+	// holding the tls.crt and tls.key used by Ironic and Inspector, e.g.
+	// issued by cert-manager. When set, the operator does not generate nor
+	// rotate its own certificate.
+	ExternalTLSSecret string `json:"externalTLSSecret,omitempty"`
+
+	// ImagePullSecrets names Secrets in the operator namespace used to pull
+	// the metal3 pod images, e.g. from a private registry the nodes have no
+	// credentials for.
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
+	// RPCAuthStrategy sets how the JSON-RPC calls between the Ironic API
+	// and conductor are authenticated, either noauth or http_basic with
+	// the operator-managed RPC credentials. When not set, the default of
+	// the Ironic image is used.
+	RPCAuthStrategy RPCAuthStrategy `json:"rpcAuthStrategy,omitempty"`
+
+	// RPCTimeout is how long the Ironic API waits for a JSON-RPC call to
+	// the conductor to complete. When not set, the default of the Ironic
+	// image is used.
+	RPCTimeout *metav1.Duration `json:"rpcTimeout,omitempty"`
+
+	// JSONRPCPort is the host port the Ironic conductor serves JSON-RPC
+	// on. Defaults to 8089.
+	JSONRPCPort *int32 `json:"jsonRPCPort,omitempty"`
+
+	// InternalTLS makes Ironic, Ironic Inspector and the
+	// baremetal-operator verify the TLS certificates of the Ironic and
+	// Inspector APIs they call, instead of skipping the verification.
+	// Defaults to false.
+	InternalTLS bool `json:"internalTLS,omitempty"`
+
+	// ResourceRequests overrides the resource requests of the metal3 and
+	// baremetal-operator containers, keyed by container name, e.g. to
+	// raise the memory request of metal3-ironic on large fleets. The given
+	// resources are merged over the defaults.
+	ResourceRequests map[string]corev1.ResourceList `json:"resourceRequests,omitempty"`
+
+	// ImageOverrides replaces the image of the metal3 and
+	// baremetal-operator containers, keyed by container name, e.g. to run
+	// metal3-ironic-inspector from a development build while the other
+	// containers keep the release images. Only meant for testing.
+	ImageOverrides map[string]string `json:"imageOverrides,omitempty"`
+
+	// TerminationGracePeriodSeconds is how long the metal3 pod is given to
+	// shut down, letting Ironic finish its in-flight node operations
+	// instead of leaving nodes in transient states. Defaults to 120.
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// MinReadySeconds is how long a new metal3 pod must be ready before it
+	// counts as available, giving Ironic time to warm up before the
+	// Provisioning reports the deployment available. Defaults to 0.
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
+	// SharedVolumePath is where the volume shared by the metal3 containers
+	// is mounted, for Ironic images expecting it elsewhere. Must be a clean
+	// absolute path. Defaults to /shared.
+	SharedVolumePath string `json:"sharedVolumePath,omitempty"`
+
+	// ExposeIronicRoute creates a Route exposing the Ironic API outside of
+	// the cluster, e.g. to manage hosts with an out-of-cluster
+	// baremetal-operator. TLS is passed through to Ironic. Ignored on
+	// clusters without the Route API. Defaults to false.
+	ExposeIronicRoute bool `json:"exposeIronicRoute,omitempty"`
+
+	// PriorityClassName is the priority class of the metal3 pod, for
+	// clusters restricting system-node-critical to platform workloads.
+	// Defaults to system-node-critical.
+	PriorityClassName *string `json:"priorityClassName,omitempty"`
+
+	// ServiceAccountName is the service account of the metal3 pod, for
+	// namespaces where it must be bound to a service account managed
+	// separately from the operator. It must exist in the operator
+	// namespace. Defaults to cluster-baremetal-operator.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// PrePullIronicImage adds an init container running the Ironic image,
+	// so that its pull happens during pod initialization instead of when
+	// the main containers start. Defaults to false.
+	PrePullIronicImage bool `json:"prePullIronicImage,omitempty"`
+
+	// CheckProvisioningInterface adds an init container failing the metal3
+	// pod early with a clear message when the ProvisioningInterface does
+	// not exist on the host. Ignored when the provisioning network is
+	// Disabled. Defaults to false.
+	CheckProvisioningInterface bool `json:"checkProvisioningInterface,omitempty"`
+
+	// RegistryMirror is a registry host, with an optional port, replacing
+	// the registry of all the images of the release payload, keeping their
+	// repository, tag and digest, e.g. mirror.example.com:5000 for
+	// disconnected installs. When not set, the images are used as is.
+	RegistryMirror string `json:"registryMirror,omitempty"`
+
+	// ProbeTuning overrides the timing of the probes of the metal3
+	// containers, e.g. to give Ironic more time to start on slow storage.
+	// When not set, each probe uses its own defaults.
+	ProbeTuning *ProbeTuning `json:"probeTuning,omitempty"`
+
+	// ExternalDHCP drops the dnsmasq container from the metal3 pod, for
+	// provisioning networks where DHCP and TFTP are served outside of the
+	// cluster. The rest of the Managed or Unmanaged provisioning, such as
+	// httpd and the static IP manager, is kept. Cannot be combined with
+	// the dnsmasq settings dhcpLeaseTime, tftpBlockSize and
+	// provisioningDNS. Defaults to false.
+	ExternalDHCP bool `json:"externalDHCP,omitempty"`
+
+	// RamdiskExtraKernelParams are appended to the kernel command line of
+	// the IPA ramdisk, e.g. console=ttyS0 or rd.break to debug hardware.
+	// Each entry is a single parameter, without whitespace or shell
+	// metacharacters. When not set, no parameters are added.
+	RamdiskExtraKernelParams []string `json:"ramdiskExtraKernelParams,omitempty"`
+
+	// SplitDeployment runs Ironic Inspector in its own metal3-inspector
+	// deployment instead of the metal3 pod, so that restarting one does
+	// not disrupt the other. The inspector pod is restarted on the node
+	// of the metal3 pod whenever that one moves, serves its port directly
+	// and prints its own ramdisk logs. Defaults to false.
+	SplitDeployment bool `json:"splitDeployment,omitempty"`
+
+	// ProvisioningVIP is a virtual IP fronting the ProvisioningIP, for
+	// topologies reaching the metal3 services through a VIP. When set, a
+	// keepalived container advertises it on the ProvisioningInterface. It
+	// must be within the ProvisioningNetworkCIDR and requires a Managed or
+	// Unmanaged provisioning network.
+	ProvisioningVIP string `json:"provisioningVIP,omitempty"`
+
+	// ProvisioningIPSource selects the address Ironic binds to with a
+	// Disabled ProvisioningNetwork and no ProvisioningIP. HostIP, the
+	// default, uses the IP of the node. Interface uses the address of the
+	// ProvisioningInterface on the node, which must then be set.
+	ProvisioningIPSource ProvisioningIPSource `json:"provisioningIPSource,omitempty"`
+
+	// DNSPolicy overrides the DNS policy of the metal3 pod. Defaults to
+	// ClusterFirstWithHostNet. Use None with DNSConfig to only resolve
+	// through the given nameservers.
+	// +kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// DNSConfig adds nameservers, searches and options to the DNS
+	// configuration of the metal3 pod, e.g. pointing disconnected
+	// provisioning networks at the internal resolver of a registry mirror.
+	// It is required with the None DNSPolicy.
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
 }
 
 // ProvisioningStatus defines the observed state of Provisioning
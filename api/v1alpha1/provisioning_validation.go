@@ -20,9 +20,16 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/strings/slices"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -32,8 +39,113 @@ type EnabledFeatures struct {
 
 var (
 	log = ctrl.Log.WithName("provisioning_validation")
+
+	conductorGroupRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	kernelParamRegexp    = regexp.MustCompile(`^[a-zA-Z0-9_.,:/=+@%-]+$`)
+	// imageReferenceRegexp matches [registry[:port]/]repository[:tag][@digest]
+	imageReferenceRegexp = regexp.MustCompile(`^[a-zA-Z0-9.-]+(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*(:[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127})?(@sha256:[a-f0-9]{64})?$`)
+
+	// reservedHostPorts are the ports already bound on the masters, which
+	// the overridable ports cannot use
+	reservedHostPorts = map[int32]string{
+		2379:  "etcd",
+		2380:  "etcd peer",
+		5050:  "ironic-inspector",
+		5051:  "ironic-inspector private",
+		6181:  "image cache",
+		6183:  "virtual media HTTPS",
+		6385:  "ironic",
+		6388:  "ironic private",
+		6443:  "Kubernetes API",
+		8084:  "image customization",
+		9447:  "baremetal-operator webhook",
+		10250: "kubelet",
+		22623: "machine-config-server",
+		22624: "machine-config-server",
+	}
+
+	// overridablePorts are the host ports of the metal3 pod that can be
+	// changed in the Provisioning CR, with their default value
+	overridablePorts = []struct {
+		field       string
+		service     string
+		defaultPort int32
+		port        func(*ProvisioningSpec) *int32
+	}{
+		{"httpPort", "httpd", 6180, func(spec *ProvisioningSpec) *int32 { return spec.HTTPPort }},
+		{"ironicPort", "ironic", 6385, func(spec *ProvisioningSpec) *int32 { return spec.IronicPort }},
+		{"inspectorPort", "ironic-inspector", 5050, func(spec *ProvisioningSpec) *int32 { return spec.InspectorPort }},
+		{"jsonRPCPort", "JSON-RPC", 8089, func(spec *ProvisioningSpec) *int32 { return spec.JSONRPCPort }},
+	}
+
+	// baremetalOperatorManagedEnv are the environment variables of the
+	// baremetal-operator container set by the operator
+	baremetalOperatorManagedEnv = []string{
+		"DEPLOY_KERNEL_URL",
+		"HTTPS_PROXY",
+		"HTTP_PROXY",
+		"IRONIC_CACERT_FILE",
+		"IRONIC_ENDPOINT",
+		"IRONIC_EXTERNAL_IP",
+		"IRONIC_EXTERNAL_URL_V6",
+		"IRONIC_INSECURE",
+		"IRONIC_INSPECTOR_ENDPOINT",
+		"LIVE_ISO_FORCE_PERSISTENT_BOOT_DEVICE",
+		"METAL3_AUTH_ROOT_DIR",
+		"NO_PROXY",
+		"OPERATOR_NAME",
+		"POD_NAME",
+		"POD_NAMESPACE",
+		"WATCH_NAMESPACE",
+	}
+
+	// resourceRequestsContainers are the containers whose requests and
+	// image can be set through ResourceRequests and ImageOverrides
+	resourceRequestsContainers = []string{
+		"machine-os-images",
+		"metal3-baremetal-operator",
+		"metal3-dnsmasq",
+		"metal3-httpd",
+		"metal3-ironic",
+		"metal3-ironic-inspector",
+		"metal3-ironic-pre-pull",
+		"metal3-keepalived",
+		"metal3-provisioning-interface-check",
+		"metal3-machine-os-downloader",
+		"metal3-ramdisk-logs",
+		"metal3-static-ip-manager",
+		"metal3-static-ip-set",
+	}
+
+	// extraHostPathContainers are the containers host directories can be
+	// mounted into through ExtraHostPathVolumes
+	extraHostPathContainers = []string{
+		"metal3-dnsmasq",
+		"metal3-httpd",
+		"metal3-ironic",
+		"metal3-ironic-inspector",
+		"metal3-ramdisk-logs",
+		"metal3-static-ip-manager",
+	}
+
+	// allowedHostPaths are the host directories ExtraHostPathVolumes may
+	// mount, with their subdirectories. Broader prefixes such as /var/lib/
+	// would expose /var/lib/kubelet, holding the secrets and tokens of every
+	// pod, or /var/lib/etcd.
+	allowedHostPaths = []string{"/var/lib/metal3", "/var/lib/tftpboot", "/var/log/journal"}
+
+	// reservedMountPaths are where the metal3 containers mount their own
+	// volumes, besides the shared volume. ExtraHostPathVolumes must neither
+	// be mounted there nor above them.
+	reservedMountPaths = []string{"/auth", "/certs", "/etc/pki/ca-trust/extracted/pem", "/var/run/secrets"}
 )
 
+// pathWithin reports whether the clean path p is dir or one of its
+// subdirectories.
+func pathWithin(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
 // ValidateBaremetalProvisioningConfig validates the contents of the provisioning resource
 func (prov *Provisioning) ValidateBaremetalProvisioningConfig(enabledFeatures EnabledFeatures) error {
 	provisioningNetworkMode := prov.getProvisioningNetworkMode()
@@ -70,6 +182,246 @@ func (prov *Provisioning) ValidateBaremetalProvisioningConfig(enabledFeatures En
 		errs = append(errs, err...)
 	}
 
+	if err := validatePreProvisioningOSDownloadURLs(prov.Spec.PreProvisioningOSDownloadURLs); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateBMCPollingOverrides(prov.Spec.BMCPollingOverrides); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateRetirementCleanSteps(prov.Spec.EnableRetirementWorkflow, prov.Spec.RetirementCleanSteps); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if strings.ContainsAny(prov.Spec.ServiceAccountTokenAudience, " \t\n") {
+		errs = append(errs, fmt.Errorf("serviceAccountTokenAudience %q must not contain whitespace", prov.Spec.ServiceAccountTokenAudience))
+	}
+
+	if prov.Spec.ImageDownloadTimeout != nil && prov.Spec.ImageDownloadTimeout.Duration < time.Second {
+		errs = append(errs, fmt.Errorf("imageDownloadTimeout must be at least 1s, got %s", prov.Spec.ImageDownloadTimeout.Duration))
+	}
+
+	if prov.Spec.DeployTimeout != nil && (prov.Spec.DeployTimeout.Duration < 10*time.Minute || prov.Spec.DeployTimeout.Duration > 24*time.Hour) {
+		errs = append(errs, fmt.Errorf("deployTimeout must be between 10m and 24h, got %s", prov.Spec.DeployTimeout.Duration))
+	}
+
+	if prov.Spec.PowerStateSyncInterval != nil && (prov.Spec.PowerStateSyncInterval.Duration < 30*time.Second || prov.Spec.PowerStateSyncInterval.Duration > time.Hour) {
+		errs = append(errs, fmt.Errorf("powerStateSyncInterval must be between 30s and 1h, got %s", prov.Spec.PowerStateSyncInterval.Duration))
+	}
+
+	if prov.Spec.InspectionTimeout != nil && (prov.Spec.InspectionTimeout.Duration < 5*time.Minute || prov.Spec.InspectionTimeout.Duration > 2*time.Hour) {
+		errs = append(errs, fmt.Errorf("inspectionTimeout must be between 5m and 2h, got %s", prov.Spec.InspectionTimeout.Duration))
+	}
+
+	switch prov.Spec.RPCAuthStrategy {
+	case "", RPCAuthStrategyNoAuth, RPCAuthStrategyHTTPBasic:
+	default:
+		errs = append(errs, fmt.Errorf("invalid rpcAuthStrategy %q", prov.Spec.RPCAuthStrategy))
+	}
+
+	if prov.Spec.RPCTimeout != nil && prov.Spec.RPCTimeout.Duration < time.Second {
+		errs = append(errs, fmt.Errorf("rpcTimeout must be at least 1s, got %s", prov.Spec.RPCTimeout.Duration))
+	}
+
+	if prov.Spec.TerminationGracePeriodSeconds != nil && *prov.Spec.TerminationGracePeriodSeconds < 0 {
+		errs = append(errs, fmt.Errorf("terminationGracePeriodSeconds must not be negative, got %d", *prov.Spec.TerminationGracePeriodSeconds))
+	}
+
+	if prov.Spec.MinReadySeconds < 0 {
+		errs = append(errs, fmt.Errorf("minReadySeconds must not be negative, got %d", prov.Spec.MinReadySeconds))
+	}
+
+	// The default path is relocated by prefix, a trailing slash or ".."
+	// would produce broken paths
+	if prov.Spec.SharedVolumePath != "" && (!path.IsAbs(prov.Spec.SharedVolumePath) || prov.Spec.SharedVolumePath != path.Clean(prov.Spec.SharedVolumePath) || prov.Spec.SharedVolumePath == "/") {
+		errs = append(errs, fmt.Errorf("sharedVolumePath %q must be a clean absolute path other than /", prov.Spec.SharedVolumePath))
+	}
+
+	if err := validateProbeTuning(prov.Spec.ProbeTuning); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if prov.Spec.RegistryMirror != "" {
+		if err := validateRegistryHost(prov.Spec.RegistryMirror); err != nil {
+			errs = append(errs, fmt.Errorf("invalid registryMirror %q: %w", prov.Spec.RegistryMirror, err))
+		}
+	}
+
+	if err := validatePortOverrides(&prov.Spec); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if prov.Spec.PriorityClassName != nil {
+		if msgs := validation.IsDNS1123Subdomain(*prov.Spec.PriorityClassName); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid priorityClassName %q: %s", *prov.Spec.PriorityClassName, strings.Join(msgs, ", ")))
+		}
+	}
+
+	switch prov.Spec.DNSPolicy {
+	case "", corev1.DNSClusterFirstWithHostNet, corev1.DNSClusterFirst, corev1.DNSDefault:
+	case corev1.DNSNone:
+		if prov.Spec.DNSConfig == nil || len(prov.Spec.DNSConfig.Nameservers) == 0 {
+			errs = append(errs, fmt.Errorf("dnsPolicy None requires dnsConfig nameservers"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported dnsPolicy %q, expected one of ClusterFirstWithHostNet, ClusterFirst, Default or None", prov.Spec.DNSPolicy))
+	}
+	if prov.Spec.DNSConfig != nil {
+		for _, nameserver := range prov.Spec.DNSConfig.Nameservers {
+			if net.ParseIP(nameserver) == nil {
+				errs = append(errs, fmt.Errorf("invalid dnsConfig nameserver %q, must be an IP address", nameserver))
+			}
+		}
+	}
+
+	if prov.Spec.DHCPLeaseTime != nil && prov.Spec.DHCPLeaseTime.Duration < 2*time.Minute {
+		errs = append(errs, fmt.Errorf("dhcpLeaseTime must be at least 2m, got %s", prov.Spec.DHCPLeaseTime.Duration))
+	}
+
+	if prov.Spec.ProvisioningInterfaceMTU != 0 && (prov.Spec.ProvisioningInterfaceMTU < 576 || prov.Spec.ProvisioningInterfaceMTU > 9000) {
+		errs = append(errs, fmt.Errorf("provisioningInterfaceMTU must be between 576 and 9000, got %d", prov.Spec.ProvisioningInterfaceMTU))
+	}
+
+	// Block size bounds from RFC 2348
+	if prov.Spec.TFTPBlockSize != nil && (*prov.Spec.TFTPBlockSize < 8 || *prov.Spec.TFTPBlockSize > 65464) {
+		errs = append(errs, fmt.Errorf("tftpBlockSize must be between 8 and 65464, got %d", *prov.Spec.TFTPBlockSize))
+	}
+
+	if prov.Spec.ExternalDHCP {
+		// These are only consumed by dnsmasq
+		if prov.Spec.DHCPLeaseTime != nil {
+			errs = append(errs, fmt.Errorf("dhcpLeaseTime cannot be set with externalDHCP"))
+		}
+		if prov.Spec.TFTPBlockSize != nil {
+			errs = append(errs, fmt.Errorf("tftpBlockSize cannot be set with externalDHCP"))
+		}
+		if prov.Spec.ProvisioningDNS {
+			errs = append(errs, fmt.Errorf("provisioningDNS cannot be set with externalDHCP"))
+		}
+	}
+
+	if prov.Spec.SharedVolumeMedium == SharedVolumeMediumMemory && prov.Spec.SharedVolumeSizeLimit == nil {
+		errs = append(errs, fmt.Errorf("sharedVolumeMedium %s requires sharedVolumeSizeLimit", SharedVolumeMediumMemory))
+	}
+
+	if prov.Spec.SharedVolumeSizeLimit != nil && prov.Spec.SharedVolumeSizeLimit.Sign() <= 0 {
+		errs = append(errs, fmt.Errorf("sharedVolumeSizeLimit must be positive"))
+	}
+
+	if prov.Spec.ConductorGroup != nil && !conductorGroupRegexp.MatchString(*prov.Spec.ConductorGroup) {
+		errs = append(errs, fmt.Errorf("invalid conductorGroup %q, expected a non-empty string of letters, digits, '-', '_' and '.'", *prov.Spec.ConductorGroup))
+	}
+
+	for _, param := range prov.Spec.RamdiskExtraKernelParams {
+		if !kernelParamRegexp.MatchString(param) {
+			errs = append(errs, fmt.Errorf("invalid ramdiskExtraKernelParams entry %q, expected a single parameter without whitespace or shell metacharacters", param))
+		}
+	}
+
+	if prov.Spec.ConductorConcurrency != 0 && (prov.Spec.ConductorConcurrency < 1 || prov.Spec.ConductorConcurrency > 100) {
+		errs = append(errs, fmt.Errorf("conductorConcurrency must be between 1 and 100, got %d", prov.Spec.ConductorConcurrency))
+	}
+
+	for _, entry := range prov.Spec.AdditionalNoProxy {
+		if entry == "" || strings.ContainsAny(entry, ", \t") {
+			errs = append(errs, fmt.Errorf("invalid additionalNoProxy entry %q", entry))
+		}
+	}
+
+	if prov.Spec.DownloadProxy != nil {
+		errs = append(errs, validateDownloadProxy(prov.Spec.DownloadProxy)...)
+	}
+
+	if prov.Spec.AdditionalTrustBundleConfigMap != "" {
+		if msgs := validation.IsDNS1123Subdomain(prov.Spec.AdditionalTrustBundleConfigMap); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid additionalTrustBundleConfigMap %q: %s", prov.Spec.AdditionalTrustBundleConfigMap, strings.Join(msgs, ", ")))
+		}
+	}
+
+	for _, name := range prov.Spec.ImagePullSecrets {
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid imagePullSecrets entry %q: %s", name, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if prov.Spec.ServiceAccountName != "" {
+		if msgs := validation.IsDNS1123Subdomain(prov.Spec.ServiceAccountName); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid serviceAccountName %q: %s", prov.Spec.ServiceAccountName, strings.Join(msgs, ", ")))
+		}
+	}
+
+	for _, namespace := range prov.Spec.WatchNamespaces {
+		if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid watchNamespaces entry %q: %s", namespace, strings.Join(msgs, ", ")))
+		}
+	}
+
+	errs = append(errs, validateExtraHostPathVolumes(prov.Spec.ExtraHostPathVolumes, prov.Spec.SharedVolumePath)...)
+
+	if prov.Spec.ExternalTLSSecret != "" {
+		if msgs := validation.IsDNS1123Subdomain(prov.Spec.ExternalTLSSecret); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid externalTLSSecret %q: %s", prov.Spec.ExternalTLSSecret, strings.Join(msgs, ", ")))
+		}
+	}
+
+	for _, env := range prov.Spec.ExtraBaremetalOperatorEnv {
+		if env.Name == "" {
+			errs = append(errs, fmt.Errorf("extraBaremetalOperatorEnv entries must have a name"))
+		} else if slices.Contains(baremetalOperatorManagedEnv, env.Name) {
+			errs = append(errs, fmt.Errorf("extraBaremetalOperatorEnv cannot set %s, which is managed by the operator", env.Name))
+		}
+	}
+
+	for name := range prov.Spec.ResourceRequests {
+		if !slices.Contains(resourceRequestsContainers, name) {
+			errs = append(errs, fmt.Errorf("invalid resourceRequests container %q, expected one of %s", name, strings.Join(resourceRequestsContainers, ", ")))
+		}
+	}
+
+	for name, image := range prov.Spec.ImageOverrides {
+		if !slices.Contains(resourceRequestsContainers, name) {
+			errs = append(errs, fmt.Errorf("invalid imageOverrides container %q, expected one of %s", name, strings.Join(resourceRequestsContainers, ", ")))
+		}
+		if !imageReferenceRegexp.MatchString(image) {
+			errs = append(errs, fmt.Errorf("invalid imageOverrides image %q for container %s", image, name))
+		}
+	}
+
+	switch prov.Spec.DeploymentStrategy {
+	case "", DeploymentStrategyRecreate, DeploymentStrategyRollingUpdate:
+	default:
+		errs = append(errs, fmt.Errorf("invalid deploymentStrategy %q", prov.Spec.DeploymentStrategy))
+	}
+
+	switch prov.Spec.ImagePullPolicy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported imagePullPolicy %q, expected one of Always, IfNotPresent or Never", prov.Spec.ImagePullPolicy))
+	}
+
+	if prov.Spec.ProvisioningVIP != "" && provisioningNetworkMode == ProvisioningNetworkDisabled {
+		errs = append(errs, fmt.Errorf("provisioningVIP requires a Managed or Unmanaged provisioningNetwork"))
+	}
+
+	switch prov.Spec.ProvisioningIPSource {
+	case "":
+	case ProvisioningIPSourceHostIP, ProvisioningIPSourceInterface:
+		if provisioningNetworkMode != ProvisioningNetworkDisabled {
+			errs = append(errs, fmt.Errorf("provisioningIPSource is only supported with a Disabled provisioningNetwork"))
+		}
+		if prov.Spec.ProvisioningIPSource == ProvisioningIPSourceInterface {
+			if prov.Spec.ProvisioningInterface == "" {
+				errs = append(errs, fmt.Errorf("provisioningIPSource Interface requires provisioningInterface to be set"))
+			}
+			if prov.Spec.ProvisioningIP != "" {
+				errs = append(errs, fmt.Errorf("provisioningIPSource Interface cannot be combined with provisioningIP"))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid provisioningIPSource %q", prov.Spec.ProvisioningIPSource))
+	}
+
 	if provisioningNetworkMode == ProvisioningNetworkDisabled {
 		// Only check network settings in Disabled mode if it's set.
 		if prov.Spec.ProvisioningNetworkCIDR == "" && prov.Spec.ProvisioningIP == "" {
@@ -87,6 +439,12 @@ func (prov *Provisioning) ValidateBaremetalProvisioningConfig(enabledFeatures En
 		errs = append(errs, err...)
 	}
 
+	if prov.Spec.ProvisioningVIP != "" && provisioningNetworkMode != ProvisioningNetworkDisabled {
+		if err := validateProvisioningVIP(prov.Spec.ProvisioningVIP, prov.Spec.ProvisioningIP, prov.Spec.ProvisioningNetworkCIDR); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	// We need to check this here because we've designed validateProvisioningNetworkSettings() to allow an empty DHCP Range.
 	if provisioningNetworkMode == ProvisioningNetworkManaged {
 		if prov.Spec.ProvisioningDHCPRange == "" {
@@ -97,6 +455,24 @@ func (prov *Provisioning) ValidateBaremetalProvisioningConfig(enabledFeatures En
 	return errors.NewAggregate(errs)
 }
 
+// ValidateProvisioningNetworkTransition rejects ProvisioningNetwork changes
+// adding or removing the containers serving the provisioning network, which
+// would break the nodes being provisioned. Only the Disabled mode runs without
+// dnsmasq and the static IP manager, so switching between Managed and
+// Unmanaged is allowed.
+func (prov *Provisioning) ValidateProvisioningNetworkTransition(old *Provisioning) error {
+	oldMode := old.getProvisioningNetworkMode()
+	newMode := prov.getProvisioningNetworkMode()
+	if oldMode == newMode {
+		return nil
+	}
+	if oldMode == ProvisioningNetworkDisabled || newMode == ProvisioningNetworkDisabled {
+		return fmt.Errorf("provisioningNetwork cannot be changed from %s to %s, only switching between %s and %s is allowed",
+			oldMode, newMode, ProvisioningNetworkManaged, ProvisioningNetworkUnmanaged)
+	}
+	return nil
+}
+
 func (prov *Provisioning) getProvisioningNetworkMode() ProvisioningNetwork {
 	provisioningNetworkMode := prov.Spec.ProvisioningNetwork
 	if provisioningNetworkMode == "" {
@@ -147,6 +523,263 @@ func validateProvisioningOSDownloadURL(uri string) []error {
 	return errs
 }
 
+func validatePreProvisioningOSDownloadURLs(urls PreProvisioningOSDownloadURLs) []error {
+	var errs []error
+
+	for _, field := range []struct {
+		name string
+		uri  string
+	}{
+		{"isoURL", urls.IsoURL},
+		{"kernelURL", urls.KernelURL},
+		{"initramfsURL", urls.InitramfsURL},
+		{"rootfsURL", urls.RootfsURL},
+	} {
+		if field.uri == "" {
+			continue
+		}
+		if strings.TrimSpace(field.uri) == "" {
+			errs = append(errs, fmt.Errorf("preProvisioningOSDownloadURLs.%s must not be blank", field.name))
+			continue
+		}
+		parsedURL, err := url.Parse(field.uri)
+		if err != nil || !parsedURL.IsAbs() || parsedURL.Host == "" {
+			errs = append(errs, fmt.Errorf("preProvisioningOSDownloadURLs.%s %q is not an absolute URL", field.name, field.uri))
+			continue
+		}
+		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			errs = append(errs, fmt.Errorf("unsupported scheme %q in preProvisioningOSDownloadURLs.%s %s", parsedURL.Scheme, field.name, field.uri))
+		}
+	}
+
+	return errs
+}
+
+func validateDownloadProxy(proxy *DownloadProxy) []error {
+	if proxy.HTTPProxy == "" && proxy.HTTPSProxy == "" {
+		return []error{fmt.Errorf("downloadProxy requires httpProxy or httpsProxy")}
+	}
+
+	var errs []error
+	for _, field := range []struct {
+		name string
+		uri  string
+	}{
+		{"httpProxy", proxy.HTTPProxy},
+		{"httpsProxy", proxy.HTTPSProxy},
+	} {
+		if field.uri == "" {
+			continue
+		}
+		parsedURL, err := url.Parse(field.uri)
+		if err != nil || !parsedURL.IsAbs() || parsedURL.Host == "" {
+			errs = append(errs, fmt.Errorf("downloadProxy.%s %q is not an absolute URL", field.name, field.uri))
+			continue
+		}
+		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			errs = append(errs, fmt.Errorf("unsupported scheme %q in downloadProxy.%s %s", parsedURL.Scheme, field.name, field.uri))
+		}
+	}
+	return errs
+}
+
+func validateExtraHostPathVolumes(volumes []HostPathVolume, sharedVolumePath string) []error {
+	var errs []error
+
+	// A mount colliding with one of the metal3 volumes produces a pod the
+	// API server rejects, or hides files the containers need
+	if sharedVolumePath == "" {
+		sharedVolumePath = "/shared"
+	}
+	reserved := append([]string{sharedVolumePath}, reservedMountPaths...)
+
+	names := map[string]bool{}
+	mountPaths := map[string]bool{}
+	for _, volume := range volumes {
+		if msgs := validation.IsDNS1123Label(volume.Name); len(msgs) > 0 || len(volume.Name) > 58 {
+			errs = append(errs, fmt.Errorf("invalid extraHostPathVolumes name %q, expected a DNS-1123 label of at most 58 characters", volume.Name))
+		} else if names[volume.Name] {
+			errs = append(errs, fmt.Errorf("duplicate extraHostPathVolumes name %q", volume.Name))
+		}
+		names[volume.Name] = true
+
+		// Clean paths only, so that ".." cannot escape the allowed directories
+		allowed := false
+		for _, dir := range allowedHostPaths {
+			if volume.HostPath == path.Clean(volume.HostPath) && pathWithin(volume.HostPath, dir) {
+				allowed = true
+			}
+		}
+		if !allowed {
+			errs = append(errs, fmt.Errorf("extraHostPathVolumes %s: hostPath %q must be a clean path within one of %s", volume.Name, volume.HostPath, strings.Join(allowedHostPaths, ", ")))
+		}
+
+		switch {
+		case !path.IsAbs(volume.MountPath) || volume.MountPath != path.Clean(volume.MountPath) || volume.MountPath == "/":
+			errs = append(errs, fmt.Errorf("extraHostPathVolumes %s: mountPath %q must be a clean absolute path other than /", volume.Name, volume.MountPath))
+		case mountPaths[volume.MountPath]:
+			errs = append(errs, fmt.Errorf("duplicate extraHostPathVolumes mountPath %q", volume.MountPath))
+		default:
+			for _, dir := range reserved {
+				if pathWithin(volume.MountPath, dir) || pathWithin(dir, volume.MountPath) {
+					errs = append(errs, fmt.Errorf("extraHostPathVolumes %s: mountPath %q collides with the %s mount of the metal3 containers", volume.Name, volume.MountPath, dir))
+				}
+			}
+		}
+		mountPaths[volume.MountPath] = true
+
+		if len(volume.Containers) == 0 {
+			errs = append(errs, fmt.Errorf("extraHostPathVolumes %s: containers must not be empty", volume.Name))
+		}
+		for _, container := range volume.Containers {
+			if !slices.Contains(extraHostPathContainers, container) {
+				errs = append(errs, fmt.Errorf("extraHostPathVolumes %s: invalid container %q, expected one of %s", volume.Name, container, strings.Join(extraHostPathContainers, ", ")))
+			}
+		}
+	}
+	return errs
+}
+
+// validatePortOverrides checks the overridden host ports are unprivileged and
+// do not collide with each other or with the other ports bound on the masters.
+func validatePortOverrides(spec *ProvisioningSpec) []error {
+	var errs []error
+
+	used := map[int32]string{}
+	for port, service := range reservedHostPorts {
+		used[port] = service
+	}
+	// The default of an overridden port is free, the others stay in use
+	for _, override := range overridablePorts {
+		delete(used, override.defaultPort)
+	}
+	for _, override := range overridablePorts {
+		if override.port(spec) == nil {
+			used[override.defaultPort] = override.service
+		}
+	}
+
+	for _, override := range overridablePorts {
+		port := override.port(spec)
+		if port == nil {
+			continue
+		}
+		if *port < 1024 || *port > 65535 {
+			errs = append(errs, fmt.Errorf("%s must be between 1024 and 65535, got %d", override.field, *port))
+		} else if service, ok := used[*port]; ok {
+			errs = append(errs, fmt.Errorf("%s %d conflicts with the %s port", override.field, *port, service))
+		} else {
+			used[*port] = override.service
+		}
+	}
+	return errs
+}
+
+func validateProbeTuning(tuning *ProbeTuning) []error {
+	var errs []error
+
+	if tuning == nil {
+		return errs
+	}
+	if tuning.InitialDelaySeconds != nil && *tuning.InitialDelaySeconds < 0 {
+		errs = append(errs, fmt.Errorf("probeTuning.initialDelaySeconds must not be negative, got %d", *tuning.InitialDelaySeconds))
+	}
+	for _, field := range []struct {
+		name  string
+		value *int32
+	}{
+		{"periodSeconds", tuning.PeriodSeconds},
+		{"timeoutSeconds", tuning.TimeoutSeconds},
+		{"failureThreshold", tuning.FailureThreshold},
+	} {
+		if field.value != nil && *field.value < 1 {
+			errs = append(errs, fmt.Errorf("probeTuning.%s must be at least 1, got %d", field.name, *field.value))
+		}
+	}
+	return errs
+}
+
+// validateRegistryHost accepts a registry host name or IP address, with an
+// optional port.
+func validateRegistryHost(registry string) error {
+	host := registry
+	if h, port, err := net.SplitHostPort(registry); err == nil {
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("invalid port %q", port)
+		}
+		host = h
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Subdomain(host); len(msgs) > 0 {
+		return fmt.Errorf("%s", strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+func validateBMCPollingOverrides(overrides map[string]string) []error {
+	var errs []error
+
+	for vendor, interval := range overrides {
+		if !slices.Contains(BMCVendors, vendor) {
+			errs = append(errs, fmt.Errorf("unknown BMC vendor %q in bmcPollingOverrides, expected one of %s", vendor, strings.Join(BMCVendors, ", ")))
+		}
+		duration, err := time.ParseDuration(interval)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid polling interval %q for BMC vendor %q in bmcPollingOverrides", interval, vendor))
+			continue
+		}
+		if duration <= 0 {
+			errs = append(errs, fmt.Errorf("polling interval for BMC vendor %q in bmcPollingOverrides must be positive", vendor))
+		}
+	}
+
+	return errs
+}
+
+func validateRetirementCleanSteps(enabled bool, steps []string) []error {
+	var errs []error
+
+	if len(steps) > 0 && !enabled {
+		return append(errs, fmt.Errorf("retirementCleanSteps requires enableRetirementWorkflow"))
+	}
+
+	for _, step := range steps {
+		parts := strings.Split(step, ".")
+		if len(parts) != 2 || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("invalid retirement clean step %q, expected interface.step", step))
+			continue
+		}
+		if !slices.Contains(CleanStepInterfaces, parts[0]) {
+			errs = append(errs, fmt.Errorf("unknown interface %q in retirement clean step %q, expected one of %s", parts[0], step, strings.Join(CleanStepInterfaces, ", ")))
+		}
+	}
+
+	return errs
+}
+
+// validateProvisioningVIP ensures the provisioning VIP is an address of the
+// provisioning network other than the provisioning IP.
+func validateProvisioningVIP(vip string, ip string, cidr string) error {
+	provisioningVIP := net.ParseIP(vip)
+	if provisioningVIP == nil {
+		return fmt.Errorf("could not parse provisioningVIP %q", vip)
+	}
+	// An invalid CIDR is already reported for the provisioning IP
+	_, provisioningCIDR, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil
+	}
+	if !provisioningCIDR.Contains(provisioningVIP) {
+		return fmt.Errorf("provisioningVIP %q is not in the range defined by the provisioningNetworkCIDR %q", vip, cidr)
+	}
+	if provisioningVIP.Equal(net.ParseIP(ip)) {
+		return fmt.Errorf("provisioningVIP %q must differ from the provisioningIP", vip)
+	}
+	return nil
+}
+
 func validateProvisioningNetworkSettings(ip string, cidr string, dhcpRange string, provisioningNetworkMode ProvisioningNetwork) []error {
 	// provisioningIP and networkCIDR are always set.  DHCP range is optional
 	// depending on mode.
@@ -213,6 +846,9 @@ func validateProvisioningNetworkSettings(ip string, cidr string, dhcpRange strin
 	end := net.ParseIP(dhcpRangeSplit[1])
 
 	if start != nil && end != nil {
+		if bytes.Compare(start, end) > 0 {
+			errs = append(errs, fmt.Errorf("invalid provisioningDHCPRange %q, start %q is after end %q", dhcpRange, start, end))
+		}
 		if bytes.Compare(provisioningIP, start) >= 0 && bytes.Compare(provisioningIP, end) <= 0 {
 			errs = append(errs, fmt.Errorf("invalid provisioningIP %q, value must be outside of the provisioningDHCPRange %q", provisioningIP, dhcpRange))
 		}
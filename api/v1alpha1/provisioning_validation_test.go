@@ -16,11 +16,16 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 const testBaremetalProvisioningCR = "test-provisioning-configuration"
@@ -121,21 +126,813 @@ func TestValidateManagedProvisioningConfig(t *testing.T) {
 			expectedMode:  ProvisioningNetworkManaged,
 			expectedMsg:   "is not part of the provisioningNetworkCIDR",
 		},
+		{
+			// DHCPRange start and end are swapped
+			name:          "InvalidManagedDHCPRangeReversed",
+			spec:          managedProvisioning().ProvisioningDHCPRange("172.30.20.101,172.30.20.11").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "start \"172.30.20.101\" is after end \"172.30.20.11\"",
+		},
 		{
 			// DHCP Range is not set
 			name:          "InvalidManagedDHCPRangeNotSet",
 			spec:          managedProvisioning().ProvisioningDHCPRange("").build(),
 			expectedError: true,
 			expectedMode:  ProvisioningNetworkManaged,
-			expectedMsg:   "provisioningDHCPRange is required in Managed mode",
+			expectedMsg:   "provisioningDHCPRange is required in Managed mode",
+		},
+		{
+			// OS URL is not http/https
+			name:          "InvalidManagedURLNotHttp",
+			spec:          managedProvisioning().ProvisioningOSDownloadURL("gopher://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "unsupported scheme",
+		},
+		{
+			// BMC polling overrides with known vendors and valid durations
+			name:          "ValidManagedBMCPollingOverrides",
+			spec:          managedProvisioning().BMCPollingOverrides(map[string]string{"idrac": "5m", "redfish": "30s"}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			// BMC polling overrides with an unknown vendor
+			name:          "InvalidManagedBMCPollingOverridesVendor",
+			spec:          managedProvisioning().BMCPollingOverrides(map[string]string{"acme": "5m"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "unknown BMC vendor",
+		},
+		{
+			// BMC polling overrides with an invalid duration
+			name:          "InvalidManagedBMCPollingOverridesInterval",
+			spec:          managedProvisioning().BMCPollingOverrides(map[string]string{"idrac": "often"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid polling interval",
+		},
+		{
+			// Retirement clean steps are in the interface.step format
+			name:          "ValidManagedRetirementCleanSteps",
+			spec:          managedProvisioning().RetirementWorkflow(true, "deploy.erase_devices_metadata").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedRetirementCleanStepsFormat",
+			spec:          managedProvisioning().RetirementWorkflow(true, "erase_devices").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "expected interface.step",
+		},
+		{
+			name:          "InvalidManagedRetirementCleanStepsInterface",
+			spec:          managedProvisioning().RetirementWorkflow(true, "vendor.erase").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "unknown interface",
+		},
+		{
+			name:          "InvalidManagedRetirementCleanStepsDisabled",
+			spec:          managedProvisioning().RetirementWorkflow(false, "deploy.erase_devices_metadata").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "requires enableRetirementWorkflow",
+		},
+		{
+			name:          "InvalidManagedServiceAccountTokenAudience",
+			spec:          managedProvisioning().ServiceAccountTokenAudience("ironic metal3").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "must not contain whitespace",
+		},
+		{
+			name:          "ValidManagedInspectionTimeout",
+			spec:          managedProvisioning().InspectionTimeout(2 * time.Hour).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedInspectionTimeoutTooShort",
+			spec:          managedProvisioning().InspectionTimeout(time.Minute).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "inspectionTimeout must be between 5m and 2h, got 1m0s",
+		},
+		{
+			name:          "InvalidManagedInspectionTimeoutTooLong",
+			spec:          managedProvisioning().InspectionTimeout(3 * time.Hour).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "inspectionTimeout must be between 5m and 2h, got 3h0m0s",
+		},
+		{
+			name:          "ValidManagedImageDownloadTimeout",
+			spec:          managedProvisioning().ImageDownloadTimeout(time.Hour).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedImageDownloadTimeout",
+			spec:          managedProvisioning().ImageDownloadTimeout(0).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "imageDownloadTimeout must be at least 1s",
+		},
+		{
+			name:          "ValidManagedDeployTimeout",
+			spec:          managedProvisioning().DeployTimeout(2 * time.Hour).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedDeployTimeoutTooShort",
+			spec:          managedProvisioning().DeployTimeout(time.Minute).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "deployTimeout must be between 10m and 24h, got 1m0s",
+		},
+		{
+			name:          "InvalidManagedDeployTimeoutTooLong",
+			spec:          managedProvisioning().DeployTimeout(48 * time.Hour).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "deployTimeout must be between 10m and 24h, got 48h0m0s",
+		},
+		{
+			name:          "ValidManagedPowerStateSyncInterval",
+			spec:          managedProvisioning().PowerStateSyncInterval(5 * time.Minute).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedPowerStateSyncIntervalTooShort",
+			spec:          managedProvisioning().PowerStateSyncInterval(10 * time.Second).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "powerStateSyncInterval must be between 30s and 1h, got 10s",
+		},
+		{
+			name:          "InvalidManagedPowerStateSyncIntervalTooLong",
+			spec:          managedProvisioning().PowerStateSyncInterval(2 * time.Hour).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "powerStateSyncInterval must be between 30s and 1h, got 2h0m0s",
+		},
+		{
+			name:          "ValidManagedDHCPLeaseTime",
+			spec:          managedProvisioning().DHCPLeaseTime(2 * time.Minute).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedDHCPLeaseTime",
+			spec:          managedProvisioning().DHCPLeaseTime(time.Minute).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "dhcpLeaseTime must be at least 2m",
+		},
+		{
+			name:          "ValidManagedTFTPBlockSize",
+			spec:          managedProvisioning().TFTPBlockSize(1468).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedTFTPBlockSize",
+			spec:          managedProvisioning().TFTPBlockSize(65465).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "tftpBlockSize must be between 8 and 65464",
+		},
+		{
+			name:          "ValidManagedExternalDHCP",
+			spec:          managedProvisioning().ExternalDHCP().build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedExternalDHCPLeaseTime",
+			spec:          managedProvisioning().ExternalDHCP().DHCPLeaseTime(30 * time.Minute).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "dhcpLeaseTime cannot be set with externalDHCP",
+		},
+		{
+			name:          "InvalidManagedExternalDHCPTFTPBlockSize",
+			spec:          managedProvisioning().ExternalDHCP().TFTPBlockSize(1468).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "tftpBlockSize cannot be set with externalDHCP",
+		},
+		{
+			name:          "ValidManagedProvisioningVIP",
+			spec:          managedProvisioning().ProvisioningVIP("172.30.20.4").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedProvisioningVIPOutsideCIDR",
+			spec:          managedProvisioning().ProvisioningVIP("172.30.21.4").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `provisioningVIP "172.30.21.4" is not in the range defined by the provisioningNetworkCIDR "172.30.20.0/24"`,
+		},
+		{
+			name:          "InvalidManagedProvisioningVIPSameAsIP",
+			spec:          managedProvisioning().ProvisioningVIP("172.30.20.3").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `provisioningVIP "172.30.20.3" must differ from the provisioningIP`,
+		},
+		{
+			name:          "InvalidManagedProvisioningVIPNotAnIP",
+			spec:          managedProvisioning().ProvisioningVIP("vip.example.com").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `could not parse provisioningVIP "vip.example.com"`,
+		},
+		{
+			name:          "InvalidManagedProvisioningIPSource",
+			spec:          managedProvisioning().ProvisioningIPSource(ProvisioningIPSourceHostIP).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "provisioningIPSource is only supported with a Disabled provisioningNetwork",
+		},
+		{
+			name:          "ValidManagedServiceAccountName",
+			spec:          managedProvisioning().ServiceAccountName("metal3").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedServiceAccountName",
+			spec:          managedProvisioning().ServiceAccountName("Metal3_SA").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `invalid serviceAccountName "Metal3_SA"`,
+		},
+		{
+			name:          "ValidManagedRamdiskExtraKernelParams",
+			spec:          managedProvisioning().RamdiskExtraKernelParams("console=ttyS0,115200n8", "rd.break", "ipa-debug=1").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedRamdiskExtraKernelParams",
+			spec:          managedProvisioning().RamdiskExtraKernelParams("console=ttyS0", "rd.break;reboot").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `invalid ramdiskExtraKernelParams entry "rd.break;reboot"`,
+		},
+		{
+			name:          "InvalidManagedRamdiskExtraKernelParamsWhitespace",
+			spec:          managedProvisioning().RamdiskExtraKernelParams("console=ttyS0 rd.break").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `invalid ramdiskExtraKernelParams entry "console=ttyS0 rd.break"`,
+		},
+		{
+			name:          "ValidManagedProvisioningInterfaceMTU",
+			spec:          managedProvisioning().ProvisioningInterfaceMTU(9000).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedProvisioningInterfaceMTU",
+			spec:          managedProvisioning().ProvisioningInterfaceMTU(500).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "provisioningInterfaceMTU must be between 576 and 9000",
+		},
+		{
+			name:          "ValidManagedConductorConcurrency",
+			spec:          managedProvisioning().ConductorConcurrency(100).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedConductorConcurrency",
+			spec:          managedProvisioning().ConductorConcurrency(101).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "conductorConcurrency must be between 1 and 100, got 101",
+		},
+		{
+			name:          "ValidManagedConductorGroup",
+			spec:          managedProvisioning().ConductorGroup("rack-1").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "EmptyManagedConductorGroup",
+			spec:          managedProvisioning().ConductorGroup("").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid conductorGroup",
+		},
+		{
+			name:          "InvalidManagedConductorGroup",
+			spec:          managedProvisioning().ConductorGroup("rack 1").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid conductorGroup",
+		},
+		{
+			name:          "ValidManagedAdditionalNoProxy",
+			spec:          managedProvisioning().AdditionalNoProxy("registry.example.com", "172.22.0.0/24").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			// Entries are comma-joined into NO_PROXY
+			name:          "InvalidManagedAdditionalNoProxy",
+			spec:          managedProvisioning().AdditionalNoProxy("a.example.com,b.example.com").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid additionalNoProxy entry",
+		},
+		{
+			name:          "ValidManagedDownloadProxy",
+			spec:          managedProvisioning().DownloadProxy("http://cache.example.com:3128", "").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedDownloadProxyEmpty",
+			spec:          managedProvisioning().DownloadProxy("", "").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "downloadProxy requires httpProxy or httpsProxy",
+		},
+		{
+			name:          "InvalidManagedDownloadProxyURL",
+			spec:          managedProvisioning().DownloadProxy("", "cache.example.com:3128").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "downloadProxy.httpsProxy",
+		},
+		{
+			name:          "ValidManagedSharedVolumeMemory",
+			spec:          managedProvisioning().SharedVolumeMedium(SharedVolumeMediumMemory, resource.NewQuantity(8<<30, resource.BinarySI)).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			// A tmpfs without size limit could take all the node memory
+			name:          "InvalidManagedSharedVolumeMemoryWithoutLimit",
+			spec:          managedProvisioning().SharedVolumeMedium(SharedVolumeMediumMemory, nil).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "sharedVolumeMedium Memory requires sharedVolumeSizeLimit",
+		},
+		{
+			name: "ValidManagedExtraHostPathVolumes",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "tftp-cache",
+				HostPath:   "/var/lib/tftpboot",
+				MountPath:  "/debug/tftpboot",
+				Containers: []string{"metal3-ironic", "metal3-dnsmasq"},
+			}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name: "InvalidManagedExtraHostPathVolumesPrefix",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "etc",
+				HostPath:   "/etc/kubernetes",
+				MountPath:  "/debug/etc",
+				Containers: []string{"metal3-ironic"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "hostPath \"/etc/kubernetes\" must be a clean path within one of /var/lib/metal3, /var/lib/tftpboot, /var/log/journal",
+		},
+		{
+			// The kubelet directory holds the secrets and tokens of every pod
+			name: "InvalidManagedExtraHostPathVolumesKubelet",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "kubelet",
+				HostPath:   "/var/lib/kubelet",
+				MountPath:  "/debug/kubelet",
+				Containers: []string{"metal3-ironic"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "hostPath \"/var/lib/kubelet\" must be a clean path within one of",
+		},
+		{
+			// Sibling directories sharing the prefix are not allowed
+			name: "InvalidManagedExtraHostPathVolumesSibling",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "sibling",
+				HostPath:   "/var/lib/metal3-other",
+				MountPath:  "/debug/other",
+				Containers: []string{"metal3-ironic"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "must be a clean path within one of",
+		},
+		{
+			name: "InvalidManagedExtraHostPathVolumesSharedMount",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "images",
+				HostPath:   "/var/lib/metal3/images",
+				MountPath:  "/shared/html/images",
+				Containers: []string{"metal3-httpd"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "mountPath \"/shared/html/images\" collides with the /shared mount",
+		},
+		{
+			name: "InvalidManagedExtraHostPathVolumesRelocatedSharedMount",
+			spec: managedProvisioning().SharedVolumePath("/var/lib/ironic-shared").ExtraHostPathVolumes(HostPathVolume{
+				Name:       "journal",
+				HostPath:   "/var/log/journal",
+				MountPath:  "/var/lib/ironic-shared",
+				Containers: []string{"metal3-ironic"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "collides with the /var/lib/ironic-shared mount",
+		},
+		{
+			// Mounting above a metal3 volume would hide it
+			name: "InvalidManagedExtraHostPathVolumesParentMount",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "journal",
+				HostPath:   "/var/log/journal",
+				MountPath:  "/etc/pki",
+				Containers: []string{"metal3-ironic"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "collides with the /etc/pki/ca-trust/extracted/pem mount",
+		},
+		{
+			name: "InvalidManagedExtraHostPathVolumesDuplicateMount",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "journal",
+				HostPath:   "/var/log/journal",
+				MountPath:  "/debug/host",
+				Containers: []string{"metal3-ironic"},
+			}, HostPathVolume{
+				Name:       "tftp-cache",
+				HostPath:   "/var/lib/tftpboot",
+				MountPath:  "/debug/host",
+				Containers: []string{"metal3-dnsmasq"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "duplicate extraHostPathVolumes mountPath \"/debug/host\"",
+		},
+		{
+			name: "InvalidManagedExtraHostPathVolumesEscape",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "escape",
+				HostPath:   "/var/lib/metal3/../../../etc",
+				MountPath:  "/debug/etc",
+				Containers: []string{"metal3-ironic"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "must be a clean path within one of",
+		},
+		{
+			name: "InvalidManagedExtraHostPathVolumesContainer",
+			spec: managedProvisioning().ExtraHostPathVolumes(HostPathVolume{
+				Name:       "logs",
+				HostPath:   "/var/log/journal",
+				MountPath:  "/debug/logs",
+				Containers: []string{"metal3-baremetal-operator"},
+			}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid container \"metal3-baremetal-operator\"",
+		},
+		{
+			name:          "ValidManagedImagePullSecrets",
+			spec:          managedProvisioning().ImagePullSecrets("registry-creds").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedImagePullSecrets",
+			spec:          managedProvisioning().ImagePullSecrets("Registry_Creds").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid imagePullSecrets entry \"Registry_Creds\"",
+		},
+		{
+			name:          "ValidManagedWatchNamespaces",
+			spec:          managedProvisioning().WatchNamespaces("openshift-machine-api", "edge-hosts").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedWatchNamespaces",
+			spec:          managedProvisioning().WatchNamespaces("openshift-machine-api", "Edge_Hosts").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid watchNamespaces entry \"Edge_Hosts\"",
+		},
+		{
+			name:          "ValidManagedExternalTLSSecret",
+			spec:          managedProvisioning().ExternalTLSSecret("ironic-cert").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedExternalTLSSecret",
+			spec:          managedProvisioning().ExternalTLSSecret("Ironic_Cert").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid externalTLSSecret",
+		},
+		{
+			name:          "ValidManagedAdditionalTrustBundleConfigMap",
+			spec:          managedProvisioning().AdditionalTrustBundleConfigMap("registry-ca").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedAdditionalTrustBundleConfigMap",
+			spec:          managedProvisioning().AdditionalTrustBundleConfigMap("Registry_CA").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid additionalTrustBundleConfigMap",
+		},
+		{
+			name:          "ValidManagedRPC",
+			spec:          managedProvisioning().RPC(RPCAuthStrategyHTTPBasic, time.Minute).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedRPCAuthStrategy",
+			spec:          managedProvisioning().RPC("keystone", time.Minute).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid rpcAuthStrategy",
+		},
+		{
+			name:          "InvalidManagedRPCTimeout",
+			spec:          managedProvisioning().RPC(RPCAuthStrategyNoAuth, 0).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "rpcTimeout must be at least 1s",
+		},
+		{
+			name:          "ValidManagedResourceRequests",
+			spec:          managedProvisioning().ResourceRequests("metal3-ironic", corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedResourceRequestsContainer",
+			spec:          managedProvisioning().ResourceRequests("metal3-ironic-conductor", corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid resourceRequests container \"metal3-ironic-conductor\"",
+		},
+		{
+			name:          "ValidManagedImageOverrides",
+			spec:          managedProvisioning().ImageOverride("metal3-ironic-inspector", "quay.io/metal3-io/ironic:latest").ImageOverride("metal3-ironic", "registry.example.com:5000/ironic@sha256:"+strings.Repeat("a", 64)).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedImageOverridesContainer",
+			spec:          managedProvisioning().ImageOverride("metal3-ironic-conductor", "quay.io/metal3-io/ironic:latest").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid imageOverrides container \"metal3-ironic-conductor\"",
+		},
+		{
+			name:          "InvalidManagedImageOverridesImage",
+			spec:          managedProvisioning().ImageOverride("metal3-ironic-inspector", "quay.io/metal3-io/ironic latest").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid imageOverrides image \"quay.io/metal3-io/ironic latest\" for container metal3-ironic-inspector",
+		},
+		{
+			name:          "ValidManagedMinReadySeconds",
+			spec:          managedProvisioning().MinReadySeconds(30).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ValidManagedSharedVolumePath",
+			spec:          managedProvisioning().SharedVolumePath("/var/lib/metal3").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedSharedVolumePathRelative",
+			spec:          managedProvisioning().SharedVolumePath("shared").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "sharedVolumePath \"shared\" must be a clean absolute path other than /",
+		},
+		{
+			name:          "InvalidManagedSharedVolumePathUnclean",
+			spec:          managedProvisioning().SharedVolumePath("/var/lib/metal3/").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "sharedVolumePath \"/var/lib/metal3/\" must be a clean absolute path other than /",
+		},
+		{
+			name:          "InvalidManagedSharedVolumePathRoot",
+			spec:          managedProvisioning().SharedVolumePath("/").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "sharedVolumePath \"/\" must be a clean absolute path other than /",
+		},
+		{
+			name:          "InvalidManagedMinReadySeconds",
+			spec:          managedProvisioning().MinReadySeconds(-1).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "minReadySeconds must not be negative, got -1",
+		},
+		{
+			name:          "InvalidManagedTerminationGracePeriod",
+			spec:          managedProvisioning().TerminationGracePeriodSeconds(-1).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "terminationGracePeriodSeconds must not be negative",
+		},
+		{
+			name:          "ValidManagedExtraBaremetalOperatorEnv",
+			spec:          managedProvisioning().ExtraBaremetalOperatorEnv(corev1.EnvVar{Name: "BMO_CONCURRENCY", Value: "10"}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedExtraBaremetalOperatorEnv",
+			spec:          managedProvisioning().ExtraBaremetalOperatorEnv(corev1.EnvVar{Name: "WATCH_NAMESPACE", Value: "default"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "extraBaremetalOperatorEnv cannot set WATCH_NAMESPACE, which is managed by the operator",
+		},
+		{
+			name:          "ValidManagedProbeTuning",
+			spec:          managedProvisioning().ProbeTuning(&ProbeTuning{PeriodSeconds: pointer.Int32Ptr(20), InitialDelaySeconds: pointer.Int32Ptr(0)}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedProbeTuning",
+			spec:          managedProvisioning().ProbeTuning(&ProbeTuning{TimeoutSeconds: pointer.Int32Ptr(0)}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "probeTuning.timeoutSeconds must be at least 1, got 0",
+		},
+		{
+			name:          "ValidManagedRegistryMirror",
+			spec:          managedProvisioning().RegistryMirror("mirror.example.com:5000").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ValidManagedRegistryMirrorIPv6",
+			spec:          managedProvisioning().RegistryMirror("[fd00::1]:5000").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedRegistryMirrorPath",
+			spec:          managedProvisioning().RegistryMirror("mirror.example.com/ocp").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid registryMirror \"mirror.example.com/ocp\"",
+		},
+		{
+			name:          "InvalidManagedRegistryMirrorPort",
+			spec:          managedProvisioning().RegistryMirror("mirror.example.com:http").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid registryMirror \"mirror.example.com:http\": invalid port \"http\"",
+		},
+		{
+			name:          "ValidManagedHTTPPort",
+			spec:          managedProvisioning().HTTPPort(8180).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedHTTPPortReserved",
+			spec:          managedProvisioning().HTTPPort(6385).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "httpPort 6385 conflicts with the ironic port",
+		},
+		{
+			name:          "InvalidManagedHTTPPortPrivileged",
+			spec:          managedProvisioning().HTTPPort(80).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "httpPort must be between 1024 and 65535, got 80",
+		},
+		{
+			name:          "ValidManagedPortOverrides",
+			spec:          managedProvisioning().IronicPort(7385).InspectorPort(7050).JSONRPCPort(7089).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ValidManagedPortOverridesSwapped",
+			spec:          managedProvisioning().IronicPort(5050).InspectorPort(6385).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedIronicPortPrivileged",
+			spec:          managedProvisioning().IronicPort(443).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "ironicPort must be between 1024 and 65535, got 443",
 		},
 		{
-			// OS URL is not http/https
-			name:          "InvalidManagedURLNotHttp",
-			spec:          managedProvisioning().ProvisioningOSDownloadURL("gopher://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
+			name:          "InvalidManagedIronicPortDefaultInspector",
+			spec:          managedProvisioning().IronicPort(5050).build(),
 			expectedError: true,
 			expectedMode:  ProvisioningNetworkManaged,
-			expectedMsg:   "unsupported scheme",
+			expectedMsg:   "ironicPort 5050 conflicts with the ironic-inspector port",
+		},
+		{
+			name:          "InvalidManagedInspectorPortCollision",
+			spec:          managedProvisioning().IronicPort(7385).InspectorPort(7385).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "inspectorPort 7385 conflicts with the ironic port",
+		},
+		{
+			name:          "InvalidManagedJSONRPCPortHTTPPort",
+			spec:          managedProvisioning().JSONRPCPort(6180).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "jsonRPCPort 6180 conflicts with the httpd port",
+		},
+		{
+			name:          "InvalidManagedIronicPortReserved",
+			spec:          managedProvisioning().IronicPort(6388).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "ironicPort 6388 conflicts with the ironic private port",
+		},
+		{
+			name:          "ValidManagedPriorityClassName",
+			spec:          managedProvisioning().PriorityClassName("openshift-user-critical").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedPriorityClassName",
+			spec:          managedProvisioning().PriorityClassName("").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "invalid priorityClassName \"\"",
+		},
+		{
+			name:          "ValidManagedDNSConfig",
+			spec:          managedProvisioning().DNS(corev1.DNSNone, &corev1.PodDNSConfig{Nameservers: []string{"192.168.111.1", "fd00::1"}}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedDNSConfigNameserver",
+			spec:          managedProvisioning().DNS("", &corev1.PodDNSConfig{Nameservers: []string{"dns.example.com"}}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `invalid dnsConfig nameserver "dns.example.com", must be an IP address`,
+		},
+		{
+			name:          "InvalidManagedDNSPolicyNoneWithoutNameservers",
+			spec:          managedProvisioning().DNS(corev1.DNSNone, nil).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "dnsPolicy None requires dnsConfig nameservers",
+		},
+		{
+			name:          "InvalidManagedDNSPolicy",
+			spec:          managedProvisioning().DNS("Custom", nil).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   `unsupported dnsPolicy "Custom"`,
+		},
+		{
+			// Only the Kubernetes pull policies are accepted
+			name:          "InvalidManagedImagePullPolicy",
+			spec:          managedProvisioning().ImagePullPolicy("Sometimes").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "unsupported imagePullPolicy",
 		},
 	}
 	for _, tc := range tCases {
@@ -273,6 +1070,46 @@ func TestValidateDisabledProvisioningConfig(t *testing.T) {
 			expectedError: false,
 			expectedMode:  ProvisioningNetworkDisabled,
 		},
+		{
+			name:          "InvalidDisabledProvisioningVIP",
+			spec:          disabledProvisioning().ProvisioningVIP("172.30.20.4").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkDisabled,
+			expectedMsg:   "provisioningVIP requires a Managed or Unmanaged provisioningNetwork",
+		},
+		{
+			name:          "ValidDisabledProvisioningIPSourceHostIP",
+			spec:          disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").ProvisioningIPSource(ProvisioningIPSourceHostIP).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkDisabled,
+		},
+		{
+			name:          "ValidDisabledProvisioningIPSourceInterface",
+			spec:          disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").ProvisioningInterface("eth1").ProvisioningIPSource(ProvisioningIPSourceInterface).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkDisabled,
+		},
+		{
+			name:          "InvalidDisabledProvisioningIPSourceInterfaceNoInterface",
+			spec:          disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").ProvisioningIPSource(ProvisioningIPSourceInterface).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkDisabled,
+			expectedMsg:   "provisioningIPSource Interface requires provisioningInterface to be set",
+		},
+		{
+			name:          "InvalidDisabledProvisioningIPSourceInterfaceWithIP",
+			spec:          disabledProvisioning().ProvisioningInterface("eth1").ProvisioningIPSource(ProvisioningIPSourceInterface).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkDisabled,
+			expectedMsg:   "provisioningIPSource Interface cannot be combined with provisioningIP",
+		},
+		{
+			name:          "InvalidDisabledProvisioningIPSource",
+			spec:          disabledProvisioning().ProvisioningIPSource("Node").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkDisabled,
+			expectedMsg:   `invalid provisioningIPSource "Node"`,
+		},
 		{
 			name:          "InvalidDisabledBadDownloadURL",
 			spec:          disabledProvisioning().ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.zip?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
@@ -336,6 +1173,139 @@ func TestValidateDisabledProvisioningConfig(t *testing.T) {
 	}
 }
 
+func TestValidateProvisioningIPOutsideDHCPRange(t *testing.T) {
+	tCases := []struct {
+		name          string
+		ip            string
+		cidr          string
+		dhcpRange     string
+		expectedError bool
+	}{
+		{
+			name:          "inside",
+			ip:            "172.30.20.50",
+			cidr:          "172.30.20.0/24",
+			dhcpRange:     "172.30.20.11,172.30.20.101",
+			expectedError: true,
+		},
+		{
+			name:          "range start",
+			ip:            "172.30.20.11",
+			cidr:          "172.30.20.0/24",
+			dhcpRange:     "172.30.20.11,172.30.20.101",
+			expectedError: true,
+		},
+		{
+			name:          "range end",
+			ip:            "172.30.20.101",
+			cidr:          "172.30.20.0/24",
+			dhcpRange:     "172.30.20.11, 172.30.20.101",
+			expectedError: true,
+		},
+		{
+			name:      "just before",
+			ip:        "172.30.20.10",
+			cidr:      "172.30.20.0/24",
+			dhcpRange: "172.30.20.11,172.30.20.101",
+		},
+		{
+			name:      "just after",
+			ip:        "172.30.20.102",
+			cidr:      "172.30.20.0/24",
+			dhcpRange: "172.30.20.11,172.30.20.101",
+		},
+		{
+			// A string comparison would put .9 after .101
+			name:      "shorter last octet",
+			ip:        "172.30.20.9",
+			cidr:      "172.30.20.0/24",
+			dhcpRange: "172.30.20.11,172.30.20.101",
+		},
+		{
+			name:          "IPv6 inside",
+			ip:            "fd2e:6f44:5dd8:b856::20",
+			cidr:          "fd2e:6f44:5dd8:b856::/64",
+			dhcpRange:     "fd2e:6f44:5dd8:b856::10,fd2e:6f44:5dd8:b856::ff",
+			expectedError: true,
+		},
+		{
+			name:      "IPv6 outside",
+			ip:        "fd2e:6f44:5dd8:b856::2",
+			cidr:      "fd2e:6f44:5dd8:b856::/64",
+			dhcpRange: "fd2e:6f44:5dd8:b856::10,fd2e:6f44:5dd8:b856::ff",
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateProvisioningNetworkSettings(tc.ip, tc.cidr, tc.dhcpRange, ProvisioningNetworkManaged)
+			if !tc.expectedError {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, fmt.Sprintf("invalid provisioningIP %q, value must be outside of the provisioningDHCPRange %q", tc.ip, strings.ReplaceAll(tc.dhcpRange, ", ", ",")), errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestValidatePreProvisioningOSDownloadURLs(t *testing.T) {
+	tCases := []struct {
+		name        string
+		urls        PreProvisioningOSDownloadURLs
+		expectedMsg string
+	}{
+		{
+			name: "Valid",
+			urls: PreProvisioningOSDownloadURLs{
+				IsoURL:       "http://172.22.0.1/images/rhcos-live.x86_64.iso",
+				KernelURL:    "https://172.22.0.1/images/rhcos-live-kernel-x86_64",
+				InitramfsURL: "http://172.22.0.1/images/rhcos-live-initramfs.x86_64.img",
+				RootfsURL:    "http://172.22.0.1/images/rhcos-live-rootfs.x86_64.img",
+			},
+		},
+		{
+			name:        "BadScheme",
+			urls:        PreProvisioningOSDownloadURLs{IsoURL: "htttp://172.22.0.1/images/rhcos-live.x86_64.iso"},
+			expectedMsg: "unsupported scheme \"htttp\" in preProvisioningOSDownloadURLs.isoURL",
+		},
+		{
+			name:        "RelativeURL",
+			urls:        PreProvisioningOSDownloadURLs{KernelURL: "images/rhcos-live-kernel-x86_64"},
+			expectedMsg: "preProvisioningOSDownloadURLs.kernelURL \"images/rhcos-live-kernel-x86_64\" is not an absolute URL",
+		},
+		{
+			name:        "MissingHost",
+			urls:        PreProvisioningOSDownloadURLs{RootfsURL: "http:///rhcos-live-rootfs.x86_64.img"},
+			expectedMsg: "preProvisioningOSDownloadURLs.rootfsURL \"http:///rhcos-live-rootfs.x86_64.img\" is not an absolute URL",
+		},
+		{
+			name:        "EmptyButPresent",
+			urls:        PreProvisioningOSDownloadURLs{InitramfsURL: " "},
+			expectedMsg: "preProvisioningOSDownloadURLs.initramfsURL must not be blank",
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := managedProvisioning().build()
+			spec.PreProvisioningOSDownloadURLs = tc.urls
+			baremetalCR := &Provisioning{Spec: *spec}
+			err := baremetalCR.ValidateBaremetalProvisioningConfig(EnabledFeatures{
+				ProvisioningNetwork: map[ProvisioningNetwork]bool{
+					ProvisioningNetworkManaged: true,
+				},
+			})
+			if tc.expectedMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.expectedMsg)
+			}
+		})
+	}
+}
+
 func TestValidateSupportedFeatures(t *testing.T) {
 	baremetalCR := &Provisioning{
 		TypeMeta: metav1.TypeMeta{
@@ -498,3 +1468,218 @@ func (pb *provisioningBuilder) ProvisioningOSDownloadURL(value string) *provisio
 	pb.ProvisioningSpec.ProvisioningOSDownloadURL = value
 	return pb
 }
+
+func (pb *provisioningBuilder) BMCPollingOverrides(value map[string]string) *provisioningBuilder {
+	pb.ProvisioningSpec.BMCPollingOverrides = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ImagePullPolicy(value corev1.PullPolicy) *provisioningBuilder {
+	pb.ProvisioningSpec.ImagePullPolicy = value
+	return pb
+}
+
+func (pb *provisioningBuilder) RetirementWorkflow(enabled bool, steps ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.EnableRetirementWorkflow = enabled
+	pb.ProvisioningSpec.RetirementCleanSteps = steps
+	return pb
+}
+
+func (pb *provisioningBuilder) ServiceAccountTokenAudience(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ServiceAccountTokenAudience = value
+	return pb
+}
+
+func (pb *provisioningBuilder) InspectionTimeout(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.InspectionTimeout = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) ImageDownloadTimeout(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.ImageDownloadTimeout = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) DeployTimeout(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.DeployTimeout = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) PowerStateSyncInterval(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.PowerStateSyncInterval = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) DHCPLeaseTime(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.DHCPLeaseTime = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningVIP(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningVIP = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ServiceAccountName(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.ServiceAccountName = name
+	return pb
+}
+
+func (pb *provisioningBuilder) RamdiskExtraKernelParams(params ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.RamdiskExtraKernelParams = params
+	return pb
+}
+
+func (pb *provisioningBuilder) ExternalDHCP() *provisioningBuilder {
+	pb.ProvisioningSpec.ExternalDHCP = true
+	return pb
+}
+
+func (pb *provisioningBuilder) TFTPBlockSize(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.TFTPBlockSize = &value
+	return pb
+}
+
+func (pb *provisioningBuilder) ConductorConcurrency(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.ConductorConcurrency = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ConductorGroup(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ConductorGroup = &value
+	return pb
+}
+
+func (pb *provisioningBuilder) AdditionalNoProxy(entries ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.AdditionalNoProxy = entries
+	return pb
+}
+
+func (pb *provisioningBuilder) SharedVolumeMedium(medium SharedVolumeMedium, sizeLimit *resource.Quantity) *provisioningBuilder {
+	pb.ProvisioningSpec.SharedVolumeMedium = medium
+	pb.ProvisioningSpec.SharedVolumeSizeLimit = sizeLimit
+	return pb
+}
+
+func (pb *provisioningBuilder) ExtraHostPathVolumes(volumes ...HostPathVolume) *provisioningBuilder {
+	pb.ProvisioningSpec.ExtraHostPathVolumes = volumes
+	return pb
+}
+
+func (pb *provisioningBuilder) ImagePullSecrets(names ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.ImagePullSecrets = names
+	return pb
+}
+
+func (pb *provisioningBuilder) WatchNamespaces(namespaces ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.WatchNamespaces = namespaces
+	return pb
+}
+
+func (pb *provisioningBuilder) ExternalTLSSecret(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.ExternalTLSSecret = name
+	return pb
+}
+
+func (pb *provisioningBuilder) AdditionalTrustBundleConfigMap(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.AdditionalTrustBundleConfigMap = name
+	return pb
+}
+
+func (pb *provisioningBuilder) RPC(strategy RPCAuthStrategy, timeout time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.RPCAuthStrategy = strategy
+	pb.ProvisioningSpec.RPCTimeout = &metav1.Duration{Duration: timeout}
+	return pb
+}
+
+func (pb *provisioningBuilder) ImageOverride(container, image string) *provisioningBuilder {
+	if pb.ProvisioningSpec.ImageOverrides == nil {
+		pb.ProvisioningSpec.ImageOverrides = map[string]string{}
+	}
+	pb.ProvisioningSpec.ImageOverrides[container] = image
+	return pb
+}
+
+func (pb *provisioningBuilder) SharedVolumePath(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.SharedVolumePath = value
+	return pb
+}
+
+func (pb *provisioningBuilder) MinReadySeconds(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.MinReadySeconds = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ResourceRequests(container string, requests corev1.ResourceList) *provisioningBuilder {
+	if pb.ProvisioningSpec.ResourceRequests == nil {
+		pb.ProvisioningSpec.ResourceRequests = map[string]corev1.ResourceList{}
+	}
+	pb.ProvisioningSpec.ResourceRequests[container] = requests
+	return pb
+}
+
+func (pb *provisioningBuilder) TerminationGracePeriodSeconds(value int64) *provisioningBuilder {
+	pb.ProvisioningSpec.TerminationGracePeriodSeconds = &value
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningInterfaceMTU(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningInterfaceMTU = value
+	return pb
+}
+
+func (pb *provisioningBuilder) DownloadProxy(httpProxy, httpsProxy string) *provisioningBuilder {
+	pb.ProvisioningSpec.DownloadProxy = &DownloadProxy{HTTPProxy: httpProxy, HTTPSProxy: httpsProxy}
+	return pb
+}
+
+func (pb *provisioningBuilder) PriorityClassName(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.PriorityClassName = &name
+	return pb
+}
+
+func (pb *provisioningBuilder) DNS(policy corev1.DNSPolicy, config *corev1.PodDNSConfig) *provisioningBuilder {
+	pb.ProvisioningSpec.DNSPolicy = policy
+	pb.ProvisioningSpec.DNSConfig = config
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) InspectorPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.InspectorPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) JSONRPCPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.JSONRPCPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) HTTPPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.HTTPPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) ExtraBaremetalOperatorEnv(env ...corev1.EnvVar) *provisioningBuilder {
+	pb.ProvisioningSpec.ExtraBaremetalOperatorEnv = env
+	return pb
+}
+
+func (pb *provisioningBuilder) RegistryMirror(registry string) *provisioningBuilder {
+	pb.ProvisioningSpec.RegistryMirror = registry
+	return pb
+}
+
+func (pb *provisioningBuilder) ProbeTuning(tuning *ProbeTuning) *provisioningBuilder {
+	pb.ProvisioningSpec.ProbeTuning = tuning
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningIPSource(value ProvisioningIPSource) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningIPSource = value
+	return pb
+}
@@ -47,13 +47,36 @@ func (r *Provisioning) ValidateCreate() (admission.Warnings, error) {
 		return nil, fmt.Errorf("Provisioning object is a singleton and must be named \"%s\"", ProvisioningSingletonName)
 	}
 
-	return nil, r.ValidateBaremetalProvisioningConfig(enabledFeatures)
+	return r.warnings(), r.ValidateBaremetalProvisioningConfig(enabledFeatures)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *Provisioning) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	provisioninglog.Info("validate update", "name", r.Name)
-	return nil, r.ValidateBaremetalProvisioningConfig(enabledFeatures)
+
+	if oldProvisioning, ok := old.(*Provisioning); ok {
+		if err := r.ValidateProvisioningNetworkTransition(oldProvisioning); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.warnings(), r.ValidateBaremetalProvisioningConfig(enabledFeatures)
+}
+
+// warnings returns the admission warnings for settings that are valid but
+// should not be used on production clusters.
+func (r *Provisioning) warnings() admission.Warnings {
+	var warnings admission.Warnings
+	if r.Spec.HostPID {
+		warnings = append(warnings, "hostPID is enabled on the metal3 pod: this is meant for debugging only and must be disabled once done")
+	}
+	if r.Spec.DeploymentStrategy == DeploymentStrategyRollingUpdate {
+		warnings = append(warnings, "the RollingUpdate strategy starts a second metal3 pod on another master during updates: until the old pod stops, two dnsmasq instances answer DHCP on the provisioning network and both pods hold the provisioning IP. Recreate is always used on single-node clusters")
+	}
+	if r.Spec.EnableDebugEndpoints {
+		warnings = append(warnings, "debug endpoints are enabled on the baremetal-operator: this is meant for debugging only and must be disabled once done")
+	}
+	return warnings
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -65,3 +65,97 @@ func TestProvisioningValidateCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestProvisioningValidateWarnings(t *testing.T) {
+	enabledFeatures = EnabledFeatures{
+		ProvisioningNetwork: map[ProvisioningNetwork]bool{
+			ProvisioningNetworkDisabled: true,
+		},
+	}
+
+	p := &Provisioning{ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration"}}
+	p.Spec = *disabledProvisioning().build()
+	if warnings, _ := p.ValidateCreate(); len(warnings) != 0 {
+		t.Errorf("Provisioning.ValidateCreate() unexpected warnings = %v", warnings)
+	}
+
+	p.Spec.HostPID = true
+	warnings, err := p.ValidateUpdate(p)
+	if err != nil {
+		t.Errorf("Provisioning.ValidateUpdate() unexpected error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "hostPID") {
+		t.Errorf("Provisioning.ValidateUpdate() warnings = %v, want a hostPID warning", warnings)
+	}
+
+	p.Spec.HostPID = false
+	p.Spec.DeploymentStrategy = DeploymentStrategyRollingUpdate
+	warnings, _ = p.ValidateUpdate(p)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "RollingUpdate") || !strings.Contains(warnings[0], "DHCP") {
+		t.Errorf("Provisioning.ValidateUpdate() warnings = %v, want a RollingUpdate warning", warnings)
+	}
+}
+
+func TestProvisioningValidateUpdateNetworkTransition(t *testing.T) {
+	enabledFeatures = EnabledFeatures{
+		ProvisioningNetwork: map[ProvisioningNetwork]bool{
+			ProvisioningNetworkDisabled:  true,
+			ProvisioningNetworkUnmanaged: true,
+			ProvisioningNetworkManaged:   true,
+		},
+	}
+
+	specs := map[ProvisioningNetwork]*ProvisioningSpec{
+		ProvisioningNetworkManaged:   managedProvisioning().build(),
+		ProvisioningNetworkUnmanaged: unmanagedProvisioning().build(),
+		ProvisioningNetworkDisabled:  disabledProvisioning().build(),
+	}
+	tests := []struct {
+		from    ProvisioningNetwork
+		to      ProvisioningNetwork
+		wantErr string
+	}{
+		{from: ProvisioningNetworkManaged, to: ProvisioningNetworkManaged},
+		{from: ProvisioningNetworkManaged, to: ProvisioningNetworkUnmanaged},
+		{from: ProvisioningNetworkUnmanaged, to: ProvisioningNetworkManaged},
+		{from: ProvisioningNetworkUnmanaged, to: ProvisioningNetworkUnmanaged},
+		{from: ProvisioningNetworkDisabled, to: ProvisioningNetworkDisabled},
+		{
+			from:    ProvisioningNetworkManaged,
+			to:      ProvisioningNetworkDisabled,
+			wantErr: "provisioningNetwork cannot be changed from Managed to Disabled",
+		},
+		{
+			from:    ProvisioningNetworkUnmanaged,
+			to:      ProvisioningNetworkDisabled,
+			wantErr: "provisioningNetwork cannot be changed from Unmanaged to Disabled",
+		},
+		{
+			from:    ProvisioningNetworkDisabled,
+			to:      ProvisioningNetworkManaged,
+			wantErr: "provisioningNetwork cannot be changed from Disabled to Managed",
+		},
+		{
+			from:    ProvisioningNetworkDisabled,
+			to:      ProvisioningNetworkUnmanaged,
+			wantErr: "provisioningNetwork cannot be changed from Disabled to Unmanaged",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"To"+string(tt.to), func(t *testing.T) {
+			old := &Provisioning{ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration"}, Spec: *specs[tt.from]}
+			p := &Provisioning{ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration"}, Spec: *specs[tt.to]}
+			if _, err := p.ValidateUpdate(old); !errorContains(err, tt.wantErr) {
+				t.Errorf("Provisioning.ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// An unset ProvisioningNetwork defaults to Managed
+	old := &Provisioning{Spec: *managedProvisioning().build()}
+	old.Spec.ProvisioningNetwork = ""
+	p := &Provisioning{Spec: *disabledProvisioning().build()}
+	if err := p.ValidateProvisioningNetworkTransition(old); !errorContains(err, "from Managed to Disabled") {
+		t.Errorf("Provisioning.ValidateProvisioningNetworkTransition() error = %v", err)
+	}
+}
@@ -21,9 +21,27 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownloadProxy) DeepCopyInto(out *DownloadProxy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadProxy.
+func (in *DownloadProxy) DeepCopy() *DownloadProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(DownloadProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnabledFeatures) DeepCopyInto(out *EnabledFeatures) {
 	*out = *in
@@ -46,6 +64,47 @@ func (in *EnabledFeatures) DeepCopy() *EnabledFeatures {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in EnvVarList) DeepCopyInto(out *EnvVarList) {
+	{
+		in := &in
+		*out = make(EnvVarList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvVarList.
+func (in EnvVarList) DeepCopy() EnvVarList {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvVarList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostPathVolume) DeepCopyInto(out *HostPathVolume) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostPathVolume.
+func (in *HostPathVolume) DeepCopy() *HostPathVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(HostPathVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreProvisioningOSDownloadURLs) DeepCopyInto(out *PreProvisioningOSDownloadURLs) {
 	*out = *in
@@ -61,6 +120,41 @@ func (in *PreProvisioningOSDownloadURLs) DeepCopy() *PreProvisioningOSDownloadUR
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeTuning) DeepCopyInto(out *ProbeTuning) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeTuning.
+func (in *ProbeTuning) DeepCopy() *ProbeTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Provisioning) DeepCopyInto(out *Provisioning) {
 	*out = *in
@@ -128,7 +222,203 @@ func (in *ProvisioningSpec) DeepCopyInto(out *ProvisioningSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.WatchNamespaces != nil {
+		in, out := &in.WatchNamespaces, &out.WatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	out.PreProvisioningOSDownloadURLs = in.PreProvisioningOSDownloadURLs
+	if in.HTTPPort != nil {
+		in, out := &in.HTTPPort, &out.HTTPPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IronicPort != nil {
+		in, out := &in.IronicPort, &out.IronicPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InspectorPort != nil {
+		in, out := &in.InspectorPort, &out.InspectorPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BMCPollingOverrides != nil {
+		in, out := &in.BMCPollingOverrides, &out.BMCPollingOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AdditionalTolerations != nil {
+		in, out := &in.AdditionalTolerations, &out.AdditionalTolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RetirementCleanSteps != nil {
+		in, out := &in.RetirementCleanSteps, &out.RetirementCleanSteps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageDownloadTimeout != nil {
+		in, out := &in.ImageDownloadTimeout, &out.ImageDownloadTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DeployTimeout != nil {
+		in, out := &in.DeployTimeout, &out.DeployTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PowerStateSyncInterval != nil {
+		in, out := &in.PowerStateSyncInterval, &out.PowerStateSyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.InspectionTimeout != nil {
+		in, out := &in.InspectionTimeout, &out.InspectionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DHCPLeaseTime != nil {
+		in, out := &in.DHCPLeaseTime, &out.DHCPLeaseTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TFTPBlockSize != nil {
+		in, out := &in.TFTPBlockSize, &out.TFTPBlockSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InitContainerEnv != nil {
+		in, out := &in.InitContainerEnv, &out.InitContainerEnv
+		*out = make(map[string]EnvVarList, len(*in))
+		for key, val := range *in {
+			var outVal []v1.EnvVar
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(EnvVarList, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ExtraBaremetalOperatorEnv != nil {
+		in, out := &in.ExtraBaremetalOperatorEnv, &out.ExtraBaremetalOperatorEnv
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraHostPathVolumes != nil {
+		in, out := &in.ExtraHostPathVolumes, &out.ExtraHostPathVolumes
+		*out = make([]HostPathVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConductorGroup != nil {
+		in, out := &in.ConductorGroup, &out.ConductorGroup
+		*out = new(string)
+		**out = **in
+	}
+	if in.AdditionalNoProxy != nil {
+		in, out := &in.AdditionalNoProxy, &out.AdditionalNoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DownloadProxy != nil {
+		in, out := &in.DownloadProxy, &out.DownloadProxy
+		*out = new(DownloadProxy)
+		**out = **in
+	}
+	if in.SharedVolumeSizeLimit != nil {
+		in, out := &in.SharedVolumeSizeLimit, &out.SharedVolumeSizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RPCTimeout != nil {
+		in, out := &in.RPCTimeout, &out.RPCTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.JSONRPCPort != nil {
+		in, out := &in.JSONRPCPort, &out.JSONRPCPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ResourceRequests != nil {
+		in, out := &in.ResourceRequests, &out.ResourceRequests
+		*out = make(map[string]v1.ResourceList, len(*in))
+		for key, val := range *in {
+			var outVal map[v1.ResourceName]resource.Quantity
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(v1.ResourceList, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val.DeepCopy()
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ImageOverrides != nil {
+		in, out := &in.ImageOverrides, &out.ImageOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProbeTuning != nil {
+		in, out := &in.ProbeTuning, &out.ProbeTuning
+		*out = new(ProbeTuning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RamdiskExtraKernelParams != nil {
+		in, out := &in.RamdiskExtraKernelParams, &out.RamdiskExtraKernelParams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(v1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningSpec.
@@ -48,9 +48,17 @@ const (
 	// ReasonDeploymentCrashLooping indicates that the deployment is crashlooping
 	ReasonDeploymentCrashLooping StatusReason = "DeploymentCrashLooping"
 
+	// ReasonDeploymentTerminationStuck indicates that the rollout of the
+	// deployment waits on an old pod that does not finish terminating
+	ReasonDeploymentTerminationStuck StatusReason = "DeploymentTerminationStuck"
+
 	// ReasonResourceNotFound indicates that the deployment is not found
 	ReasonResourceNotFound StatusReason = "ResourceNotFound"
 
+	// ReasonHostPortConflict indicates that other pods use the host ports
+	// needed by the metal3 pod
+	ReasonHostPortConflict StatusReason = "HostPortConflict"
+
 	// ReasonProvisioningCRNotFound indicates that the provsioning CR is not found
 	ReasonProvisioningCRNotFound StatusReason = "WaitingForProvisioningCR"
 
@@ -231,11 +239,11 @@ func (r *ProvisioningReconciler) updateCOStatus(newReason StatusReason, msg, pro
 	case ReasonComplete, ReasonProvisioningCRNotFound:
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(newReason), msg))
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(newReason), progressMsg))
-	case ReasonInvalidConfiguration, ReasonDeployTimedOut, ReasonResourceNotFound:
+	case ReasonInvalidConfiguration, ReasonDeployTimedOut, ReasonResourceNotFound, ReasonHostPortConflict:
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(newReason), msg))
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(ReasonEmpty), ""))
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionTrue, string(newReason), progressMsg))
-	case ReasonDeploymentCrashLooping:
+	case ReasonDeploymentCrashLooping, ReasonDeploymentTerminationStuck:
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(newReason), msg))
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionFalse, string(newReason), msg))
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(newReason), progressMsg))
@@ -30,12 +30,14 @@ import (
 	"github.com/stretchr/stew/slice"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -52,12 +54,14 @@ import (
 	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	osconfigv1 "github.com/openshift/api/config/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	osclientset "github.com/openshift/client-go/config/clientset/versioned"
 	"github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 	"github.com/openshift/cluster-baremetal-operator/provisioning"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
 const (
@@ -71,6 +75,34 @@ const (
 	clusterConfigNamespace = "kube-system"
 	// Annotation linking a machine to a host
 	HostAnnotation = "metal3.io/BareMetalHost"
+	// Provisioning CR condition reporting images not pinned by digest
+	imageDigestsPinnedCondition = "ImageDigestsPinned"
+	// Provisioning CR condition reporting whether Ironic is serving
+	ironicAvailableCondition = "IronicAvailable"
+	// Provisioning CR condition reporting the rollout of the metal3 deployment
+	metal3DeploymentAvailableCondition = "Metal3DeploymentAvailable"
+	// Provisioning CR condition reporting whether the metal3 init containers completed
+	initializationCompleteCondition = "InitializationComplete"
+	// Provisioning CR condition warning that metal3 updates compete for host ports
+	hostPortConflictsCondition = "HostPortConflicts"
+	// Provisioning CR condition reporting the metal3 deployment is scaled down
+	pausedCondition = "Paused"
+	// Provisioning CR condition reporting an invalid trusted CA bundle of the cluster proxy
+	proxyTrustedCAValidCondition = "ProxyTrustedCAValid"
+	// Provisioning CR condition reporting metal3 was deployed without the MAC addresses of the masters
+	macAddressesDiscoveredCondition = "ProvisioningMacAddressesDiscovered"
+	// Delay before checking again for the MAC addresses of the masters
+	macAddressesRequeueDelay = time.Minute
+	// Time after which metal3 is deployed without the MAC addresses of the masters
+	macAddressesWaitTimeout = 10 * time.Minute
+	// Delay before checking again for host ports used by other pods
+	hostPortsRequeueDelay = time.Minute
+	// Delay before checking again for missing image pull secrets
+	imagePullSecretsRequeueDelay = time.Minute
+	// Delay before checking again for a missing metal3 service account
+	serviceAccountRequeueDelay = time.Minute
+	// Delay before checking again an invalid proxy trusted CA bundle
+	trustedCARequeueDelay = time.Minute
 )
 
 // ProvisioningReconciler reconciles a Provisioning object
@@ -81,21 +113,28 @@ type ProvisioningReconciler struct {
 	Scheme          *runtime.Scheme
 	OSClient        osclientset.Interface
 	KubeClient      kubernetes.Interface
+	DynamicClient   dynamic.Interface
 	ReleaseVersion  string
 	ImagesFilename  string
 	WebHookEnabled  bool
 	NetworkStack    provisioning.NetworkStackType
 	EnabledFeatures v1alpha1.EnabledFeatures
 	ResourceCache   resourceapply.ResourceCache
+
+	// macAddressesWaitStart is when the reconcile started waiting for the
+	// MAC addresses of the masters, zero when not waiting
+	macAddressesWaitStart time.Time
 }
 
 type ensureFunc func(*provisioning.ProvisioningInfo) (bool, error)
 
 // +kubebuilder:rbac:namespace=openshift-machine-api,groups="",resources=configmaps;secrets;services,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:namespace=openshift-machine-api,groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:namespace=openshift-machine-api,groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:namespace=openshift-machine-api,groups="",resources=serviceaccounts,verbs=get
 // +kubebuilder:rbac:namespace=openshift-machine-api,groups=security.openshift.io,resources=securitycontextconstraints,verbs=use
 // +kubebuilder:rbac:namespace=openshift-machine-api,groups=apps,resources=deployments;daemonsets,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:namespace=openshift-machine-api,groups=monitoring.coreos.com,resources=servicemonitors,verbs=create;watch;get;list;patch
+// +kubebuilder:rbac:namespace=openshift-machine-api,groups=monitoring.coreos.com,resources=servicemonitors,verbs=create;watch;get;list;patch;update
+// +kubebuilder:rbac:namespace=openshift-machine-api,groups=route.openshift.io,resources=routes,verbs=create;delete;get;update
 
 // +kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
 // +kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get;list;watch
@@ -104,6 +143,7 @@ type ensureFunc func(*provisioning.ProvisioningInfo) (bool, error)
 // +kubebuilder:rbac:groups=config.openshift.io,resources=clusteroperators;clusteroperators/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures;infrastructures/status,verbs=get
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;watch;list;patch
+// +kubebuilder:rbac:groups="",resources=nodes;pods,verbs=list
 // +kubebuilder:rbac:groups="",resources=configmaps;secrets;services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=machine.openshift.io,resources=machines,verbs=get;list;watch
@@ -253,6 +293,18 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 		return ctrl.Result{}, err
 	}
+	if baremetalConfig.Spec.RegistryMirror != "" {
+		containerImages = *provisioning.WithRegistryMirror(&containerImages, baremetalConfig.Spec.RegistryMirror)
+	}
+
+	if err := r.checkImageDigests(ctx, baremetalConfig, &containerImages); err != nil {
+		co_err := r.updateCOStatus(ReasonInvalidConfiguration, err.Error(), "images in images Config Map are not pinned by digest")
+		if co_err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, co_err)
+		}
+		// Not requeuing, the images only change with a new release payload.
+		return ctrl.Result{}, nil
+	}
 
 	info, err := r.provisioningInfo(ctx, baremetalConfig, &containerImages, r.readSSHKey())
 	if err != nil {
@@ -298,16 +350,74 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
+	waiting, err := r.waitForProvisioningMacAddresses(ctx, baremetalConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if waiting {
+		return ctrl.Result{RequeueAfter: macAddressesRequeueDelay}, nil
+	}
+
+	if err := provisioning.CheckImagePullSecrets(info); err != nil {
+		co_err := r.updateCOStatus(ReasonResourceNotFound, err.Error(), "Unable to apply Provisioning CR: missing image pull secret")
+		if co_err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, co_err)
+		}
+		// Secrets not created by the operator are not watched
+		return ctrl.Result{RequeueAfter: imagePullSecretsRequeueDelay}, nil
+	}
+
+	if err := provisioning.CheckMetal3ServiceAccount(info); err != nil {
+		co_err := r.updateCOStatus(ReasonResourceNotFound, err.Error(), "Unable to apply Provisioning CR: missing service account")
+		if co_err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, co_err)
+		}
+		// Service accounts are not watched
+		return ctrl.Result{RequeueAfter: serviceAccountRequeueDelay}, nil
+	}
+
+	// A malformed proxy CA bundle breaks TLS to mirrored registries without
+	// any error. Only report it: the metal3 components may not need it.
+	trustedCAMessage, err := provisioning.CheckProxyTrustedCA(info)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateProxyTrustedCACondition(ctx, baremetalConfig, trustedCAMessage); err != nil {
+		return ctrl.Result{}, err
+	}
+	if trustedCAMessage != "" {
+		// The user CA bundle is not watched
+		result.RequeueAfter = trustedCARequeueDelay
+	}
+
+	// Pods holding the host ports of the metal3 pod leave it pending without
+	// any error, so check them before applying the deployment
+	hostPortConflicts, err := provisioning.CheckMetal3HostPorts(info)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if hostPortConflicts != "" {
+		err = r.updateCOStatus(ReasonHostPortConflict, hostPortConflicts, "Waiting for the host ports of the metal3 pod")
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, err)
+		}
+		return ctrl.Result{RequeueAfter: hostPortsRequeueDelay}, nil
+	}
+
 	for _, ensureResource := range []ensureFunc{
 		provisioning.EnsureAllSecrets,
 		provisioning.EnsureMetal3Deployment,
+		provisioning.EnsureMetal3InspectorDeployment,
 		provisioning.EnsureBaremetalOperatorDeployment,
+		provisioning.EnsureBaremetalOperatorMetrics,
 		provisioning.EnsureMetal3StateService,
 		provisioning.EnsureImageCache,
 		provisioning.EnsureBaremetalOperatorWebhook,
 		provisioning.EnsureImageCustomizationService,
 		provisioning.EnsureImageCustomizationDeployment,
 		provisioning.EnsureIronicProxy,
+		provisioning.EnsureIronicRoute,
+		provisioning.DeleteStaleResources,
 	} {
 		updated, err := ensureResource(info)
 		if err != nil {
@@ -327,7 +437,8 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	// Determine the status of the baremetal deployment
-	deploymentState, err := provisioning.GetDeploymentState(r.KubeClient.AppsV1(), ComponentNamespace, baremetalConfig)
+	deploymentStatus, err := provisioning.GetDeploymentStatus(info)
+	deploymentState := deploymentStatus.State
 	if err != nil {
 		err = r.updateCOStatus(ReasonResourceNotFound, "metal3 deployment inaccessible", "")
 		if err != nil {
@@ -341,6 +452,89 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, err)
 		}
 	}
+	if deploymentState == provisioning.DeploymentCrashLoopBackOff {
+		err = r.updateCOStatus(ReasonDeploymentCrashLooping, "metal3 deployment "+deploymentStatus.Message, "")
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, err)
+		}
+	}
+	if deploymentState == provisioning.DeploymentTerminationStuck {
+		info.EventRecorder.Warningf("Metal3PodTerminationStuck", "metal3 deployment %s", deploymentStatus.Message)
+		err = r.updateCOStatus(ReasonDeploymentTerminationStuck, "metal3 deployment "+deploymentStatus.Message, "")
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, err)
+		}
+	}
+
+	deploymentCondition := operatorv1.OperatorCondition{
+		Type:    metal3DeploymentAvailableCondition,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  string(deploymentState),
+		Message: deploymentStatus.Message,
+	}
+	if deploymentStatus.Available {
+		deploymentCondition.Status = operatorv1.ConditionTrue
+	}
+	if err := r.setProvisioningCondition(ctx, baremetalConfig, deploymentCondition); err != nil {
+		return ctrl.Result{}, err
+	}
+	if provisioning.Metal3DeploymentSurges(info) {
+		err = r.setProvisioningCondition(ctx, baremetalConfig, operatorv1.OperatorCondition{
+			Type:    hostPortConflictsCondition,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "RollingUpdate",
+			Message: "metal3 updates start a second pod on another master: until the old pod stops, both answer DHCP and hold the provisioning IP",
+		})
+	} else {
+		err = r.removeProvisioningCondition(ctx, baremetalConfig, hostPortConflictsCondition)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updatePausedCondition(ctx, baremetalConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Determine whether the metal3 init containers, which download the
+	// machine OS images, have completed
+	initState, err := provisioning.GetInitializationState(info)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to determine state of metal3 initialization")
+	}
+	initCondition := operatorv1.OperatorCondition{
+		Type:   initializationCompleteCondition,
+		Status: operatorv1.ConditionFalse,
+		Reason: string(initState),
+	}
+	if initState == provisioning.InitializationComplete {
+		initCondition.Status = operatorv1.ConditionTrue
+	}
+	if err := r.setProvisioningCondition(ctx, baremetalConfig, initCondition); err != nil {
+		return ctrl.Result{}, err
+	}
+	if deploymentState == appsv1.DeploymentProgressing && initState == provisioning.InitializationInProgress {
+		err = r.updateCOStatus(ReasonSyncing, "", "Waiting for metal3 init containers to complete")
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Progressing state: %w", clusterOperatorName, err)
+		}
+	}
+
+	// Determine whether Ironic itself is serving
+	ironicState, err := provisioning.GetIronicReadyState(info)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to determine state of ironic")
+	}
+	ironicCondition := operatorv1.OperatorCondition{
+		Type:   ironicAvailableCondition,
+		Status: operatorv1.ConditionFalse,
+		Reason: string(ironicState),
+	}
+	if ironicState == provisioning.IronicReady {
+		ironicCondition.Status = operatorv1.ConditionTrue
+	}
+	if err := r.setProvisioningCondition(ctx, baremetalConfig, ironicCondition); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// Determine the status of the BMO deployment
 	bmoState, err := provisioning.GetBaremetalOperatorDeploymentState(r.KubeClient.AppsV1(), ComponentNamespace, baremetalConfig)
@@ -384,6 +578,101 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return result, nil
 }
 
+// checkImageDigests enforces RequireImageDigests, recording the result in the
+// ImageDigestsPinned condition of the Provisioning CR.
+func (r *ProvisioningReconciler) checkImageDigests(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, images *provisioning.Images) error {
+	var validationErr error
+	if provConfig.Spec.RequireImageDigests {
+		validationErr = provisioning.ValidateImageDigests(images)
+	}
+
+	conditions := append([]operatorv1.OperatorCondition{}, provConfig.Status.Conditions...)
+	switch {
+	case !provConfig.Spec.RequireImageDigests:
+		v1helpers.RemoveOperatorCondition(&conditions, imageDigestsPinnedCondition)
+	case validationErr != nil:
+		v1helpers.SetOperatorCondition(&conditions, operatorv1.OperatorCondition{
+			Type:    imageDigestsPinnedCondition,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "ImagesNotPinned",
+			Message: validationErr.Error(),
+		})
+	default:
+		v1helpers.SetOperatorCondition(&conditions, operatorv1.OperatorCondition{
+			Type:   imageDigestsPinnedCondition,
+			Status: operatorv1.ConditionTrue,
+			Reason: "ImagesPinned",
+		})
+	}
+
+	if !equality.Semantic.DeepEqual(conditions, provConfig.Status.Conditions) {
+		provConfig.Status.Conditions = conditions
+		if err := r.Client.Status().Update(ctx, provConfig); err != nil {
+			return fmt.Errorf("unable to update %s condition: %w", imageDigestsPinnedCondition, err)
+		}
+	}
+	return validationErr
+}
+
+// updatePausedCondition reports on the Provisioning CR that the metal3
+// deployment is scaled down to zero, removing the condition on resume.
+func (r *ProvisioningReconciler) updatePausedCondition(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning) error {
+	if !provConfig.Spec.Paused {
+		return r.removeProvisioningCondition(ctx, provConfig, pausedCondition)
+	}
+	return r.setProvisioningCondition(ctx, provConfig, operatorv1.OperatorCondition{
+		Type:    pausedCondition,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Paused",
+		Message: "the metal3 deployment is scaled down to zero replicas",
+	})
+}
+
+// updateProxyTrustedCACondition reports on the Provisioning CR that the
+// trusted CA bundle of the cluster proxy is invalid, removing the condition
+// once it is fixed.
+func (r *ProvisioningReconciler) updateProxyTrustedCACondition(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, message string) error {
+	if message == "" {
+		return r.removeProvisioningCondition(ctx, provConfig, proxyTrustedCAValidCondition)
+	}
+	return r.setProvisioningCondition(ctx, provConfig, operatorv1.OperatorCondition{
+		Type:    proxyTrustedCAValidCondition,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "InvalidBundle",
+		Message: message,
+	})
+}
+
+// setProvisioningCondition sets a condition on the Provisioning CR status,
+// only updating the resource when the condition changed.
+func (r *ProvisioningReconciler) setProvisioningCondition(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, condition operatorv1.OperatorCondition) error {
+	conditions := append([]operatorv1.OperatorCondition{}, provConfig.Status.Conditions...)
+	v1helpers.SetOperatorCondition(&conditions, condition)
+	if equality.Semantic.DeepEqual(conditions, provConfig.Status.Conditions) {
+		return nil
+	}
+	provConfig.Status.Conditions = conditions
+	if err := r.Client.Status().Update(ctx, provConfig); err != nil {
+		return fmt.Errorf("unable to update %s condition: %w", condition.Type, err)
+	}
+	return nil
+}
+
+// removeProvisioningCondition removes a condition from the Provisioning CR
+// status, only updating the resource when the condition was present.
+func (r *ProvisioningReconciler) removeProvisioningCondition(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, conditionType string) error {
+	if v1helpers.FindOperatorCondition(provConfig.Status.Conditions, conditionType) == nil {
+		return nil
+	}
+	conditions := append([]operatorv1.OperatorCondition{}, provConfig.Status.Conditions...)
+	v1helpers.RemoveOperatorCondition(&conditions, conditionType)
+	provConfig.Status.Conditions = conditions
+	if err := r.Client.Status().Update(ctx, provConfig); err != nil {
+		return fmt.Errorf("unable to remove %s condition: %w", conditionType, err)
+	}
+	return nil
+}
+
 func (r *ProvisioningReconciler) provisioningInfo(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, images *provisioning.Images, sshkey string) (*provisioning.ProvisioningInfo, error) {
 	proxy, err := r.OSClient.ConfigV1().Proxies().Get(ctx, "cluster", metav1.GetOptions{})
 	if err != nil {
@@ -396,6 +685,11 @@ func (r *ProvisioningReconciler) provisioningInfo(ctx context.Context, provConfi
 
 	enableBaremetalWebhook := provisioning.BaremetalWebhookDependenciesReady(r.OSClient)
 
+	singleNode, err := provisioning.IsSingleNodeTopology(r.OSClient)
+	if err != nil {
+		return nil, err
+	}
+
 	return &provisioning.ProvisioningInfo{
 		Client:                  r.KubeClient,
 		EventRecorder:           events.NewLoggingEventRecorder(ComponentName),
@@ -407,7 +701,9 @@ func (r *ProvisioningReconciler) provisioningInfo(ctx context.Context, provConfi
 		NetworkStack:            r.NetworkStack,
 		SSHKey:                  sshkey,
 		BaremetalWebhookEnabled: enableBaremetalWebhook,
+		SingleNodeTopology:      singleNode,
 		OSClient:                r.OSClient,
+		DynamicClient:           r.DynamicClient,
 		ResourceCache:           r.ResourceCache,
 	}, nil
 }
@@ -437,7 +733,7 @@ func (r *ProvisioningReconciler) checkForCRDeletion(ctx context.Context, info *p
 			return false, nil
 		}
 
-		if err := r.deleteMetal3Resources(info); err != nil {
+		if err := provisioning.DeleteMetal3Resources(info); err != nil {
 			return false, errors.Wrap(err, "failed to delete metal3 resource")
 		}
 		// Remove our finalizer from the list and update it.
@@ -449,35 +745,6 @@ func (r *ProvisioningReconciler) checkForCRDeletion(ctx context.Context, info *p
 	}
 }
 
-// Delete Secrets and the Metal3 Deployment objects
-func (r *ProvisioningReconciler) deleteMetal3Resources(info *provisioning.ProvisioningInfo) error {
-	if err := provisioning.DeleteAllSecrets(info); err != nil {
-		return errors.Wrap(err, "failed to delete one or more metal3 secrets")
-	}
-	if err := provisioning.DeleteValidatingWebhook(info); err != nil {
-		return errors.Wrap(err, "failed to delete validatingwebhook and service")
-	}
-	if err := provisioning.DeleteMetal3Deployment(info); err != nil {
-		return errors.Wrap(err, "failed to delete metal3 deployment")
-	}
-	if err := provisioning.DeleteMetal3StateService(info); err != nil {
-		return errors.Wrap(err, "failed to delete metal3 service")
-	}
-	if err := provisioning.DeleteImageCache(info); err != nil {
-		return errors.Wrap(err, "failed to delete metal3 image cache")
-	}
-	if err := provisioning.DeleteImageCustomizationService(info); err != nil {
-		return errors.Wrap(err, "failed to delete metal3 image customization service")
-	}
-	if err := provisioning.DeleteImageCustomizationDeployment(info); err != nil {
-		return errors.Wrap(err, "failed to delete metal3 image customization deployment")
-	}
-	if err := provisioning.DeleteIronicProxy(info); err != nil {
-		return errors.Wrap(err, "failed to delete ironic proxy")
-	}
-	return nil
-}
-
 func (r *ProvisioningReconciler) networkStackFromServiceNetwork(ctx context.Context) (provisioning.NetworkStackType, error) {
 	ns := provisioning.NetworkStackType(0)
 	network, err := r.OSClient.ConfigV1().Networks().Get(ctx, "cluster", metav1.GetOptions{})
@@ -542,16 +809,72 @@ func getMachineByHost(name string, consumerRef *corev1.ObjectReference) string {
 	return consumerRef.Name
 }
 
+func (r *ProvisioningReconciler) listMasterMachines(ctx context.Context) (*machinev1beta1.MachineList, error) {
+	machines := &machinev1beta1.MachineList{}
+	labelReq, _ := labels.NewRequirement("machine.openshift.io/cluster-api-machine-role", selection.Equals, []string{"master"})
+	if err := r.Client.List(ctx, machines, &client.ListOptions{LabelSelector: labels.NewSelector().Add(*labelReq)}); err != nil {
+		return nil, errors.Wrap(err, "cannot list master machines")
+	}
+	return machines, nil
+}
+
+// waitForProvisioningMacAddresses reports whether the metal3 resources must
+// wait for the MAC addresses of the masters, which identify the provisioning
+// interface when ProvisioningInterface is not set. They are discovered from
+// the BareMetalHosts of the masters and can be missing during the early
+// reconciles. Without master Machines there is nothing to discover them
+// from, and the BareMetalHosts may never get a BootMACAddress, so the wait
+// is bounded: metal3 is then deployed without them, as it always was, and
+// the ProvisioningMacAddressesDiscovered condition tells the user to set
+// provisioningMacAddresses.
+func (r *ProvisioningReconciler) waitForProvisioningMacAddresses(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning) (bool, error) {
+	if provConfig.Spec.ProvisioningNetwork == metal3iov1alpha1.ProvisioningNetworkDisabled ||
+		provConfig.Spec.ProvisioningInterface != "" ||
+		len(provConfig.Spec.ProvisioningMacAddresses) != 0 {
+		r.macAddressesWaitStart = time.Time{}
+		return false, r.removeProvisioningCondition(ctx, provConfig, macAddressesDiscoveredCondition)
+	}
+
+	machines, err := r.listMasterMachines(ctx)
+	if err != nil {
+		return false, err
+	}
+	reason := "NoMasterMachines"
+	if len(machines.Items) > 0 {
+		if r.macAddressesWaitStart.IsZero() {
+			r.macAddressesWaitStart = time.Now()
+		}
+		if time.Since(r.macAddressesWaitStart) < macAddressesWaitTimeout {
+			klog.Info("Waiting for the provisioning MAC addresses of the masters")
+			if err := r.updateCOStatus(ReasonSyncing, "", "Waiting for the provisioning MAC addresses of the masters"); err != nil {
+				return true, fmt.Errorf("unable to put %q ClusterOperator in Progressing state: %w", clusterOperatorName, err)
+			}
+			return true, nil
+		}
+		reason = "DiscoveryTimedOut"
+	}
+
+	klog.Info("No provisioning MAC addresses found for the masters, deploying metal3 without them")
+	if err := r.updateCOStatus(ReasonSyncing, "", "Applying metal3 resources without the provisioning MAC addresses of the masters"); err != nil {
+		return false, fmt.Errorf("unable to put %q ClusterOperator in Progressing state: %w", clusterOperatorName, err)
+	}
+	return false, r.setProvisioningCondition(ctx, provConfig, operatorv1.OperatorCondition{
+		Type:    macAddressesDiscoveredCondition,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  reason,
+		Message: "no provisioning MAC addresses were discovered for the masters, set provisioningMacAddresses if the metal3 pod fails to start",
+	})
+}
+
 func (r *ProvisioningReconciler) updateProvisioningMacAddresses(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning) error {
 	if len(provConfig.Spec.ProvisioningMacAddresses) != 0 {
 		return nil
 	}
 
-	machines := machinev1beta1.MachineList{}
 	bmhNames := []string{}
-	labelReq, _ := labels.NewRequirement("machine.openshift.io/cluster-api-machine-role", selection.Equals, []string{"master"})
-	if err := r.Client.List(ctx, &machines, &client.ListOptions{LabelSelector: labels.NewSelector().Add(*labelReq)}); err != nil {
-		return errors.Wrap(err, "cannot list master machines")
+	machines, err := r.listMasterMachines(ctx)
+	if err != nil {
+		return err
 	}
 	if len(machines.Items) < 1 {
 		klog.Info("No Machines with cluster-api-machine-role=master found, set provisioningMacAddresses if the metal3 pod fails to start")
@@ -3,20 +3,25 @@ package controllers
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 	"github.com/openshift/cluster-baremetal-operator/provisioning"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
 func setUpSchemeForReconciler() *runtime.Scheme {
@@ -318,3 +323,162 @@ func TestUpdateProvisioningMacAddresses(t *testing.T) {
 	assert.NoError(t, err, "ProvisioningReconciler.updateProvisioningMacAddresses()")
 	assert.ElementsMatch(t, baremetalCR.Spec.ProvisioningMacAddresses, want)
 }
+
+func TestWaitForProvisioningMacAddresses(t *testing.T) {
+	testCases := []struct {
+		name              string
+		network           metal3iov1alpha1.ProvisioningNetwork
+		iface             string
+		macAddresses      []string
+		noMasterMachines  bool
+		waitingSince      time.Duration
+		expectedWaiting   bool
+		expectedCondition string
+	}{
+		{
+			name:            "ManagedWithoutMacAddresses",
+			network:         metal3iov1alpha1.ProvisioningNetworkManaged,
+			expectedWaiting: true,
+		},
+		{
+			name:            "UnmanagedWithoutMacAddresses",
+			network:         metal3iov1alpha1.ProvisioningNetworkUnmanaged,
+			expectedWaiting: true,
+		},
+		{
+			// Nothing to discover the MAC addresses from
+			name:              "ManagedWithoutMasterMachines",
+			network:           metal3iov1alpha1.ProvisioningNetworkManaged,
+			noMasterMachines:  true,
+			expectedCondition: "NoMasterMachines",
+		},
+		{
+			name:              "ManagedWaitTimedOut",
+			network:           metal3iov1alpha1.ProvisioningNetworkManaged,
+			waitingSince:      macAddressesWaitTimeout,
+			expectedCondition: "DiscoveryTimedOut",
+		},
+		{
+			name:         "ManagedWithMacAddresses",
+			network:      metal3iov1alpha1.ProvisioningNetworkManaged,
+			macAddresses: []string{"00:3d:25:45:bf:e5"},
+		},
+		{
+			name:    "ManagedWithInterface",
+			network: metal3iov1alpha1.ProvisioningNetworkManaged,
+			iface:   "eth1",
+		},
+		{
+			name:    "UnmanagedWithInterface",
+			network: metal3iov1alpha1.ProvisioningNetworkUnmanaged,
+			iface:   "eth1",
+		},
+		{
+			name:    "DisabledWithoutMacAddresses",
+			network: metal3iov1alpha1.ProvisioningNetworkDisabled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sc := setUpSchemeForReconciler()
+			provConfig := &metal3iov1alpha1.Provisioning{
+				ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+				Spec: metal3iov1alpha1.ProvisioningSpec{
+					ProvisioningNetwork:      tc.network,
+					ProvisioningInterface:    tc.iface,
+					ProvisioningMacAddresses: tc.macAddresses,
+				},
+			}
+			objects := []client.Object{provConfig}
+			if !tc.noMasterMachines {
+				objects = append(objects, &machinev1beta1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "master-0",
+						Namespace: ComponentNamespace,
+						Labels:    map[string]string{"machine.openshift.io/cluster-api-machine-role": "master"},
+					},
+				})
+			}
+			r := &ProvisioningReconciler{
+				Scheme:   sc,
+				Client:   fakeclient.NewClientBuilder().WithScheme(sc).WithObjects(objects...).WithStatusSubresource(provConfig).Build(),
+				OSClient: fakeconfigclientset.NewSimpleClientset(),
+			}
+			co, _ := r.createClusterOperator()
+			r.OSClient = fakeconfigclientset.NewSimpleClientset(co)
+			if tc.waitingSince != 0 {
+				r.macAddressesWaitStart = time.Now().Add(-tc.waitingSince)
+			}
+
+			waiting, err := r.waitForProvisioningMacAddresses(context.TODO(), provConfig)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedWaiting, waiting)
+
+			gotCO, _ := r.OSClient.ConfigV1().ClusterOperators().Get(context.Background(), clusterOperatorName, metav1.GetOptions{})
+			progressing := v1helpers.FindStatusCondition(gotCO.Status.Conditions, configv1.OperatorProgressing)
+			switch {
+			case tc.expectedWaiting:
+				assert.Equal(t, configv1.ConditionTrue, progressing.Status)
+				assert.Equal(t, "Waiting for the provisioning MAC addresses of the masters", progressing.Message)
+			case tc.expectedCondition != "":
+				// metal3 is deployed anyway, as before the wait was added
+				assert.Equal(t, configv1.ConditionTrue, progressing.Status)
+				assert.Equal(t, "Applying metal3 resources without the provisioning MAC addresses of the masters", progressing.Message)
+			default:
+				assert.True(t, progressing == nil || progressing.Status != configv1.ConditionTrue)
+			}
+
+			condition := operatorv1helpers.FindOperatorCondition(provConfig.Status.Conditions, macAddressesDiscoveredCondition)
+			if tc.expectedCondition == "" {
+				assert.Nil(t, condition)
+			} else if assert.NotNil(t, condition) {
+				assert.Equal(t, operatorv1.ConditionFalse, condition.Status)
+				assert.Equal(t, tc.expectedCondition, condition.Reason)
+				assert.Contains(t, condition.Message, "set provisioningMacAddresses")
+			}
+		})
+	}
+}
+
+func TestUpdatePausedCondition(t *testing.T) {
+	sc := setUpSchemeForReconciler()
+	provConfig := &metal3iov1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+		Spec:       metal3iov1alpha1.ProvisioningSpec{Paused: true},
+	}
+	r := &ProvisioningReconciler{
+		Scheme: sc,
+		Client: fakeclient.NewClientBuilder().WithScheme(sc).WithObjects(provConfig).WithStatusSubresource(provConfig).Build(),
+	}
+
+	assert.NoError(t, r.updatePausedCondition(context.TODO(), provConfig))
+	condition := operatorv1helpers.FindOperatorCondition(provConfig.Status.Conditions, pausedCondition)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, operatorv1.ConditionTrue, condition.Status)
+	}
+
+	provConfig.Spec.Paused = false
+	assert.NoError(t, r.updatePausedCondition(context.TODO(), provConfig))
+	assert.Nil(t, operatorv1helpers.FindOperatorCondition(provConfig.Status.Conditions, pausedCondition))
+}
+
+func TestUpdateProxyTrustedCACondition(t *testing.T) {
+	sc := setUpSchemeForReconciler()
+	provConfig := &metal3iov1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+	}
+	r := &ProvisioningReconciler{
+		Scheme: sc,
+		Client: fakeclient.NewClientBuilder().WithScheme(sc).WithObjects(provConfig).WithStatusSubresource(provConfig).Build(),
+	}
+
+	assert.NoError(t, r.updateProxyTrustedCACondition(context.TODO(), provConfig, "invalid ca-bundle.crt"))
+	condition := operatorv1helpers.FindOperatorCondition(provConfig.Status.Conditions, proxyTrustedCAValidCondition)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, operatorv1.ConditionFalse, condition.Status)
+		assert.Equal(t, "invalid ca-bundle.crt", condition.Message)
+	}
+
+	assert.NoError(t, r.updateProxyTrustedCACondition(context.TODO(), provConfig, ""))
+	assert.Nil(t, operatorv1helpers.FindOperatorCondition(provConfig.Status.Conditions, proxyTrustedCAValidCondition))
+}
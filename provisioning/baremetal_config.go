@@ -18,6 +18,8 @@ package provisioning
 import (
 	"fmt"
 	"net"
+	"sort"
+	"strconv"
 	"strings"
 
 	"k8s.io/utils/pointer"
@@ -43,11 +45,18 @@ var (
 	vmediaHttpsPort                = "VMEDIA_TLS_PORT"
 	dnsIP                          = "DNS_IP"
 	dhcpRange                      = "DHCP_RANGE"
+	dhcpLeaseTime                  = "DHCP_LEASE_TIME"
+	tftpBlockSize                  = "TFTP_BLOCK_SIZE"
+	provisioningInterfaceMTU       = "PROVISIONING_INTERFACE_MTU"
+	dnsmasqIPStack                 = "DNSMASQ_IP_STACK"
+	dnsmasqEnableRA                = "DNSMASQ_ENABLE_RA"
 	machineImageUrl                = "RHCOS_IMAGE_URL"
 	ipOptions                      = "IP_OPTIONS"
 	bootIsoSource                  = "IRONIC_BOOT_ISO_SOURCE"
 	useUnixSocket                  = "unix"
 	useProvisioningDNS             = "provisioning"
+	bmcPollingOverrides            = "IRONIC_BMC_POLLING_OVERRIDES"
+	ironicCallbackUrl              = "IRONIC_EXTERNAL_CALLBACK_URL"
 )
 
 func getDHCPRange(config *metal3iov1alpha1.ProvisioningSpec) *string {
@@ -74,26 +83,45 @@ func getProvisioningIPCIDR(config *metal3iov1alpha1.ProvisioningSpec) *string {
 	return nil
 }
 
-func getDeployKernelUrl() *string {
-	deployKernelUrl := fmt.Sprintf("file://%s/%s", imageSharedDir, baremetalKernelSubPath)
+func getDeployKernelUrl(config *metal3iov1alpha1.ProvisioningSpec) *string {
+	// The kernel is read by Ironic from the shared volume of the metal3 pod
+	deployKernelUrl := fmt.Sprintf("file://%s/%s", relocateSharedPath(imageSharedDir, getSharedVolumePath(config)), baremetalKernelSubPath)
 	return &deployKernelUrl
 }
 
 // TODO(dtantsur): these two can be removed once we no longer have ironic/inspector split
 
-func getIronicEndpoint() *string {
-	ironicEndpoint := fmt.Sprintf("https://localhost:%d/%s", baremetalIronicPort, baremetalIronicEndpointSubpath)
+func getIronicEndpoint(config *metal3iov1alpha1.ProvisioningSpec) *string {
+	ironicEndpoint := fmt.Sprintf("https://localhost:%d/%s", getIronicPort(config), baremetalIronicEndpointSubpath)
 	return &ironicEndpoint
 }
 
-func getIronicInspectorEndpoint() *string {
-	ironicInspectorEndpoint := fmt.Sprintf("https://localhost:%d/%s", baremetalIronicInspectorPort, baremetalIronicEndpointSubpath)
+func getIronicInspectorEndpoint(config *metal3iov1alpha1.ProvisioningSpec) *string {
+	ironicInspectorEndpoint := fmt.Sprintf("https://localhost:%d/%s", getInspectorPort(config), baremetalIronicEndpointSubpath)
 	return &ironicInspectorEndpoint
 }
 
+// getIronicPort returns the port the Ironic API is exposed on, by httpd or
+// by ironic-proxy in the proxy mode.
+func getIronicPort(config *metal3iov1alpha1.ProvisioningSpec) int {
+	if config.IronicPort != nil {
+		return int(*config.IronicPort)
+	}
+	return baremetalIronicPort
+}
+
+// getInspectorPort returns the port the Ironic Inspector API is exposed on,
+// by httpd or by ironic-proxy in the proxy mode.
+func getInspectorPort(config *metal3iov1alpha1.ProvisioningSpec) int {
+	if config.InspectorPort != nil {
+		return int(*config.InspectorPort)
+	}
+	return baremetalIronicInspectorPort
+}
+
 func getControlPlanePorts(info *ProvisioningInfo) (ironicPort int, inspectorPort int) {
-	ironicPort = baremetalIronicPort
-	inspectorPort = baremetalIronicInspectorPort
+	ironicPort = getIronicPort(&info.ProvConfig.Spec)
+	inspectorPort = getInspectorPort(&info.ProvConfig.Spec)
 	if UseIronicProxy(&info.ProvConfig.Spec) {
 		// Direct access to real services behind the proxy.
 		ironicPort = ironicPrivatePort
@@ -109,6 +137,15 @@ func getControlPlaneEndpoints(info *ProvisioningInfo) (ironicEndpoint string, in
 	return
 }
 
+// getHttpPort returns the port httpd serves on, as set in the environment
+// of the containers.
+func getHttpPort(config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.HTTPPort != nil {
+		return strconv.Itoa(int(*config.HTTPPort))
+	}
+	return baremetalHttpPort
+}
+
 func getProvisioningOSDownloadURL(config *metal3iov1alpha1.ProvisioningSpec) *string {
 	if config.ProvisioningOSDownloadURL != "" {
 		return &(config.ProvisioningOSDownloadURL)
@@ -123,6 +160,38 @@ func getBootIsoSource(config *metal3iov1alpha1.ProvisioningSpec) *string {
 	return nil
 }
 
+// getBMCPollingOverrides serializes the per-vendor polling intervals as a
+// comma separated list of vendor:interval pairs, sorted by vendor.
+func getBMCPollingOverrides(config *metal3iov1alpha1.ProvisioningSpec) *string {
+	vendors := make([]string, 0, len(config.BMCPollingOverrides))
+	for vendor := range config.BMCPollingOverrides {
+		vendors = append(vendors, vendor)
+	}
+	sort.Strings(vendors)
+
+	overrides := make([]string, 0, len(vendors))
+	for _, vendor := range vendors {
+		overrides = append(overrides, fmt.Sprintf("%s:%s", vendor, config.BMCPollingOverrides[vendor]))
+	}
+	return pointer.StringPtr(strings.Join(overrides, ","))
+}
+
+// getIronicCallbackURL returns the URL the ramdisk uses to reach the conductor
+// over the provisioning network, with IPv6 literals bracketed. It returns nil
+// when the callback does not go through the provisioning IP, in which case the
+// Ironic image derives the URL from the host IP.
+func getIronicCallbackURL(config *metal3iov1alpha1.ProvisioningSpec) *string {
+	if config.ProvisioningNetwork == metal3iov1alpha1.ProvisioningNetworkDisabled || config.VirtualMediaViaExternalNetwork {
+		return nil
+	}
+	ip := net.ParseIP(config.ProvisioningIP)
+	if ip == nil {
+		return nil
+	}
+	callbackURL := fmt.Sprintf("https://%s", net.JoinHostPort(ip.String(), strconv.Itoa(getIronicPort(config))))
+	return &callbackURL
+}
+
 func getMetal3DeploymentConfig(name string, baremetalConfig *metal3iov1alpha1.ProvisioningSpec) *string {
 	switch name {
 	case provisioningIP:
@@ -132,13 +201,13 @@ func getMetal3DeploymentConfig(name string, baremetalConfig *metal3iov1alpha1.Pr
 	case provisioningMacAddresses:
 		return pointer.StringPtr(strings.Join(baremetalConfig.ProvisioningMacAddresses, ","))
 	case deployKernelUrl:
-		return getDeployKernelUrl()
+		return getDeployKernelUrl(baremetalConfig)
 	case ironicEndpoint:
-		return getIronicEndpoint()
+		return getIronicEndpoint(baremetalConfig)
 	case ironicInspectorEndpoint:
-		return getIronicInspectorEndpoint()
+		return getIronicInspectorEndpoint(baremetalConfig)
 	case httpPort:
-		return pointer.StringPtr(baremetalHttpPort)
+		return pointer.StringPtr(getHttpPort(baremetalConfig))
 	case vmediaHttpsPort:
 		return pointer.StringPtr(baremetalVmediaHttpsPort)
 	case dhcpRange:
@@ -147,6 +216,10 @@ func getMetal3DeploymentConfig(name string, baremetalConfig *metal3iov1alpha1.Pr
 		return getProvisioningOSDownloadURL(baremetalConfig)
 	case bootIsoSource:
 		return getBootIsoSource(baremetalConfig)
+	case bmcPollingOverrides:
+		return getBMCPollingOverrides(baremetalConfig)
+	case ironicCallbackUrl:
+		return getIronicCallbackURL(baremetalConfig)
 	}
 	return nil
 }
@@ -19,8 +19,13 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
@@ -74,6 +79,12 @@ func TestGetMetal3DeploymentConfig(t *testing.T) {
 			spec:          unmanagedProvisioning().build(),
 			expectedValue: "6180",
 		},
+		{
+			name:          "Managed HttpPort override",
+			configName:    httpPort,
+			spec:          managedProvisioning().HTTPPort(8180).build(),
+			expectedValue: "8180",
+		},
 		{
 			name:          "Managed DHCPRange",
 			configName:    dhcpRange,
@@ -92,6 +103,24 @@ func TestGetMetal3DeploymentConfig(t *testing.T) {
 			spec:          disabledProvisioning().build(),
 			expectedValue: "",
 		},
+		{
+			name:          "Managed BMCPollingOverrides",
+			configName:    bmcPollingOverrides,
+			spec:          managedProvisioning().BMCPollingOverrides(map[string]string{"ilo": "90s", "idrac": "5m"}).build(),
+			expectedValue: "idrac:5m,ilo:90s",
+		},
+		{
+			name:          "Managed IronicCallbackUrl",
+			configName:    ironicCallbackUrl,
+			spec:          managedProvisioning().build(),
+			expectedValue: "https://172.30.20.3:6385",
+		},
+		{
+			name:          "Managed IPv6 IronicCallbackUrl",
+			configName:    ironicCallbackUrl,
+			spec:          managedIPv6Provisioning().build(),
+			expectedValue: "https://[fd2e:6f44:5dd8:b856::2]:6385",
+		},
 		{
 			name:          "Disabled RhcosImageUrl",
 			configName:    machineImageUrl,
@@ -240,6 +269,299 @@ func (pb *provisioningBuilder) ProvisioningDNS(value bool) *provisioningBuilder
 	return pb
 }
 
+func (pb *provisioningBuilder) Paused(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.Paused = value
+	return pb
+}
+
+func (pb *provisioningBuilder) NodeSelector(value map[string]string) *provisioningBuilder {
+	pb.ProvisioningSpec.NodeSelector = value
+	return pb
+}
+
+func (pb *provisioningBuilder) Affinity(value *corev1.Affinity) *provisioningBuilder {
+	pb.ProvisioningSpec.Affinity = value
+	return pb
+}
+
+func (pb *provisioningBuilder) EnableDebugEndpoints(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.EnableDebugEndpoints = value
+	return pb
+}
+
+func (pb *provisioningBuilder) NetworkInterface(value metal3iov1alpha1.NetworkInterface) *provisioningBuilder {
+	pb.ProvisioningSpec.NetworkInterface = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ImagePullPolicy(value corev1.PullPolicy) *provisioningBuilder {
+	pb.ProvisioningSpec.ImagePullPolicy = value
+	return pb
+}
+
+func (pb *provisioningBuilder) RetirementWorkflow(enabled bool, steps ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.EnableRetirementWorkflow = enabled
+	pb.ProvisioningSpec.RetirementCleanSteps = steps
+	return pb
+}
+
+func (pb *provisioningBuilder) ServiceAccountTokenAudience(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ServiceAccountTokenAudience = value
+	return pb
+}
+
+func (pb *provisioningBuilder) InspectionTimeout(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.InspectionTimeout = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) ImageDownloadTimeout(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.ImageDownloadTimeout = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) DeployTimeout(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.DeployTimeout = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) PowerStateSyncInterval(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.PowerStateSyncInterval = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) HardenSecurityContext(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.HardenSecurityContext = value
+	return pb
+}
+
+func (pb *provisioningBuilder) DisableHostPorts(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.DisableHostPorts = value
+	return pb
+}
+
+func (pb *provisioningBuilder) DHCPLeaseTime(value time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.DHCPLeaseTime = &metav1.Duration{Duration: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) TFTPBlockSize(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.TFTPBlockSize = &value
+	return pb
+}
+
+func (pb *provisioningBuilder) SoftwareRAIDRoot(enabled bool) *provisioningBuilder {
+	pb.ProvisioningSpec.EnableSoftwareRAIDRoot = enabled
+	return pb
+}
+
+func (pb *provisioningBuilder) InitContainerEnv(name string, env ...corev1.EnvVar) *provisioningBuilder {
+	if pb.ProvisioningSpec.InitContainerEnv == nil {
+		pb.ProvisioningSpec.InitContainerEnv = map[string]metal3iov1alpha1.EnvVarList{}
+	}
+	pb.ProvisioningSpec.InitContainerEnv[name] = env
+	return pb
+}
+
+func (pb *provisioningBuilder) ConductorConcurrency(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.ConductorConcurrency = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ConductorGroup(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ConductorGroup = &value
+	return pb
+}
+
+func (pb *provisioningBuilder) AdditionalNoProxy(entries ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.AdditionalNoProxy = entries
+	return pb
+}
+
+func (pb *provisioningBuilder) SharedVolumeMedium(medium metal3iov1alpha1.SharedVolumeMedium, sizeLimit *resource.Quantity) *provisioningBuilder {
+	pb.ProvisioningSpec.SharedVolumeMedium = medium
+	pb.ProvisioningSpec.SharedVolumeSizeLimit = sizeLimit
+	return pb
+}
+
+func (pb *provisioningBuilder) ExternalTLSSecret(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.ExternalTLSSecret = name
+	return pb
+}
+
+func (pb *provisioningBuilder) AdditionalTrustBundleConfigMap(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.AdditionalTrustBundleConfigMap = name
+	return pb
+}
+
+func (pb *provisioningBuilder) DisableSeccompProfile(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.DisableSeccompProfile = value
+	return pb
+}
+
+func (pb *provisioningBuilder) RPC(strategy metal3iov1alpha1.RPCAuthStrategy, timeout time.Duration) *provisioningBuilder {
+	pb.ProvisioningSpec.RPCAuthStrategy = strategy
+	pb.ProvisioningSpec.RPCTimeout = &metav1.Duration{Duration: timeout}
+	return pb
+}
+
+func (pb *provisioningBuilder) InternalTLS(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.InternalTLS = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ImageOverride(container, image string) *provisioningBuilder {
+	if pb.ProvisioningSpec.ImageOverrides == nil {
+		pb.ProvisioningSpec.ImageOverrides = map[string]string{}
+	}
+	pb.ProvisioningSpec.ImageOverrides[container] = image
+	return pb
+}
+
+func (pb *provisioningBuilder) SharedVolumePath(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.SharedVolumePath = value
+	return pb
+}
+
+func (pb *provisioningBuilder) MinReadySeconds(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.MinReadySeconds = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ResourceRequests(container string, requests corev1.ResourceList) *provisioningBuilder {
+	if pb.ProvisioningSpec.ResourceRequests == nil {
+		pb.ProvisioningSpec.ResourceRequests = map[string]corev1.ResourceList{}
+	}
+	pb.ProvisioningSpec.ResourceRequests[container] = requests
+	return pb
+}
+
+func (pb *provisioningBuilder) TerminationGracePeriodSeconds(value int64) *provisioningBuilder {
+	pb.ProvisioningSpec.TerminationGracePeriodSeconds = &value
+	return pb
+}
+
+func (pb *provisioningBuilder) PrePullIronicImage() *provisioningBuilder {
+	pb.ProvisioningSpec.PrePullIronicImage = true
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningInterfaceMTU(value int32) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningInterfaceMTU = value
+	return pb
+}
+
+func (pb *provisioningBuilder) DownloadProxy(httpProxy, httpsProxy string) *provisioningBuilder {
+	pb.ProvisioningSpec.DownloadProxy = &metal3iov1alpha1.DownloadProxy{HTTPProxy: httpProxy, HTTPSProxy: httpsProxy}
+	return pb
+}
+
+func (pb *provisioningBuilder) PriorityClassName(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.PriorityClassName = &name
+	return pb
+}
+
+func (pb *provisioningBuilder) DNS(policy corev1.DNSPolicy, config *corev1.PodDNSConfig) *provisioningBuilder {
+	pb.ProvisioningSpec.DNSPolicy = policy
+	pb.ProvisioningSpec.DNSConfig = config
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) InspectorPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.InspectorPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) JSONRPCPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.JSONRPCPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) HTTPPort(port int32) *provisioningBuilder {
+	pb.ProvisioningSpec.HTTPPort = &port
+	return pb
+}
+
+func (pb *provisioningBuilder) ExtraBaremetalOperatorEnv(env ...corev1.EnvVar) *provisioningBuilder {
+	pb.ProvisioningSpec.ExtraBaremetalOperatorEnv = env
+	return pb
+}
+
+func (pb *provisioningBuilder) CheckProvisioningInterface() *provisioningBuilder {
+	pb.ProvisioningSpec.CheckProvisioningInterface = true
+	return pb
+}
+
+func (pb *provisioningBuilder) DeploymentStrategy(strategy metal3iov1alpha1.DeploymentStrategy) *provisioningBuilder {
+	pb.ProvisioningSpec.DeploymentStrategy = strategy
+	return pb
+}
+
+func (pb *provisioningBuilder) ProbeTuning(tuning *metal3iov1alpha1.ProbeTuning) *provisioningBuilder {
+	pb.ProvisioningSpec.ProbeTuning = tuning
+	return pb
+}
+
+func (pb *provisioningBuilder) HostPID(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.HostPID = value
+	return pb
+}
+
+func (pb *provisioningBuilder) BMCPollingOverrides(value map[string]string) *provisioningBuilder {
+	pb.ProvisioningSpec.BMCPollingOverrides = value
+	return pb
+}
+
+func (pb *provisioningBuilder) AdditionalTolerations(value ...corev1.Toleration) *provisioningBuilder {
+	pb.ProvisioningSpec.AdditionalTolerations = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningVIP(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningVIP = value
+	return pb
+}
+
+func (pb *provisioningBuilder) SplitDeployment() *provisioningBuilder {
+	pb.ProvisioningSpec.SplitDeployment = true
+	return pb
+}
+
+func (pb *provisioningBuilder) ServiceAccountName(name string) *provisioningBuilder {
+	pb.ProvisioningSpec.ServiceAccountName = name
+	return pb
+}
+
+func (pb *provisioningBuilder) PreProvisioningOSDownloadURLs(value metal3iov1alpha1.PreProvisioningOSDownloadURLs) *provisioningBuilder {
+	pb.ProvisioningSpec.PreProvisioningOSDownloadURLs = value
+	return pb
+}
+
+func (pb *provisioningBuilder) RamdiskExtraKernelParams(params ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.RamdiskExtraKernelParams = params
+	return pb
+}
+
+func (pb *provisioningBuilder) ExternalDHCP() *provisioningBuilder {
+	pb.ProvisioningSpec.ExternalDHCP = true
+	return pb
+}
+
+func (pb *provisioningBuilder) LogFormat(value metal3iov1alpha1.LogFormat) *provisioningBuilder {
+	pb.ProvisioningSpec.LogFormat = value
+	return pb
+}
+
+func (pb *provisioningBuilder) LogLevel(value metal3iov1alpha1.LogLevel) *provisioningBuilder {
+	pb.ProvisioningSpec.LogLevel = value
+	return pb
+}
+
 func enableMultiNamespace() *provisioningBuilder {
 	return &provisioningBuilder{
 		metal3iov1alpha1.ProvisioningSpec{
@@ -271,6 +593,59 @@ func (pb *provisioningBuilder) WatchAllNamespaces(value bool) *provisioningBuild
 	return pb
 }
 
+func (pb *provisioningBuilder) ExtraHostPathVolumes(volumes ...metal3iov1alpha1.HostPathVolume) *provisioningBuilder {
+	pb.ProvisioningSpec.ExtraHostPathVolumes = volumes
+	return pb
+}
+
+func (pb *provisioningBuilder) ImagePullSecrets(names ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.ImagePullSecrets = names
+	return pb
+}
+
+func (pb *provisioningBuilder) WatchNamespaces(namespaces ...string) *provisioningBuilder {
+	pb.ProvisioningSpec.WatchNamespaces = namespaces
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningIPSource(value metal3iov1alpha1.ProvisioningIPSource) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningIPSource = value
+	return pb
+}
+
+func TestGetIronicCallbackURL(t *testing.T) {
+	tCases := []struct {
+		name     string
+		spec     *metal3iov1alpha1.ProvisioningSpec
+		expected *string
+	}{
+		{
+			name:     "IPv4",
+			spec:     managedProvisioning().build(),
+			expected: pointer.StringPtr("https://172.30.20.3:6385"),
+		},
+		{
+			name:     "IPv6",
+			spec:     managedProvisioning().ProvisioningIP("fd2e:6f44:5dd8:b856::2").ProvisioningNetworkCIDR("fd2e:6f44:5dd8:b856::/64").build(),
+			expected: pointer.StringPtr("https://[fd2e:6f44:5dd8:b856::2]:6385"),
+		},
+		{
+			// Not an IP, the Ironic image derives the URL from the host IP
+			name: "hostname",
+			spec: managedProvisioning().ProvisioningIP("metal3.example.com").build(),
+		},
+		{
+			name: "Disabled",
+			spec: disabledProvisioning().build(),
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getIronicCallbackURL(tc.spec))
+		})
+	}
+}
+
 func TestWatchAllNamespaces(t *testing.T) {
 	tCases := []struct {
 		name          string
@@ -18,6 +18,8 @@ package provisioning
 import (
 	"context"
 	"fmt"
+	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -25,13 +27,17 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	utilnet "k8s.io/utils/net"
 	"k8s.io/utils/pointer"
+	"k8s.io/utils/strings/slices"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
 
 	configv1 "github.com/openshift/api/config/v1"
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
@@ -43,6 +49,7 @@ const (
 	metal3AppName                    = "metal3"
 	baremetalDeploymentName          = "metal3"
 	baremetalSharedVolume            = "metal3-shared"
+	defaultMetal3ServiceAccountName  = "cluster-baremetal-operator"
 	metal3AuthRootDir                = "/auth"
 	metal3TlsRootDir                 = "/certs"
 	ironicCredentialsVolume          = "metal3-ironic-basic-auth"
@@ -56,6 +63,7 @@ const (
 	inspectorInsecureEnvVar          = "IRONIC_INSPECTOR_INSECURE"
 	ironicKernelParamsEnvVar         = "IRONIC_KERNEL_PARAMS"
 	ironicCertEnvVar                 = "IRONIC_CACERT_FILE"
+	inspectorCertEnvVar              = "IRONIC_INSPECTOR_CACERT_FILE"
 	sshKeyEnvVar                     = "IRONIC_RAMDISK_SSH_KEY"
 	externalIpEnvVar                 = "IRONIC_EXTERNAL_IP"
 	externalUrlEnvVar                = "IRONIC_EXTERNAL_URL_V6"
@@ -68,8 +76,29 @@ const (
 	cboOwnedAnnotation               = "baremetal.openshift.io/owned"
 	cboLabelName                     = "baremetal.openshift.io/cluster-baremetal-operator"
 	externalTrustBundleConfigMapName = "cbo-trusted-ca"
+	trustedCAVolumeName              = "trusted-ca"
 	pullSecretEnvVar                 = "IRONIC_AGENT_PULL_SECRET" // #nosec
 	forceInspectorEnvVar             = "USE_IRONIC_INSPECTOR"
+	ironicLogLevelEnvVar             = "IRONIC_LOG_LEVEL"
+	ironicDebugEnvVar                = "OS_DEFAULT__DEBUG"
+	ironicUseJSONEnvVar              = "OS_DEFAULT__USE_JSON"
+	ironicNetworkInterfaceEnvVar     = "OS_DEFAULT__DEFAULT_NETWORK_INTERFACE"
+	ironicAutomatedCleanEnvVar       = "OS_CONDUCTOR__AUTOMATED_CLEAN"
+	ironicDeployTimeoutEnvVar        = "OS_CONDUCTOR__DEPLOY_CALLBACK_TIMEOUT"
+	ironicDownloadTimeoutEnvVar      = "OS_DEFAULT__WEBSERVER_CONNECTION_TIMEOUT"
+	ironicPowerSyncIntervalEnvVar    = "OS_CONDUCTOR__SYNC_POWER_STATE_INTERVAL"
+	inspectorTimeoutEnvVar           = "OS_DEFAULT__TIMEOUT"
+	ironicContainerName              = "metal3-ironic"
+	inspectorContainerName           = "metal3-ironic-inspector"
+	ramdiskLogsContainerName         = "metal3-ramdisk-logs"
+	machineOsDownloaderContainerName = "metal3-machine-os-downloader"
+	ironicConductorGroupEnvVar       = "OS_CONDUCTOR__CONDUCTOR_GROUP"
+	ironicMaxConcurrentDeployEnvVar  = "OS_CONDUCTOR__MAX_CONCURRENT_DEPLOY"
+	ironicRPCAuthStrategyEnvVar      = "OS_JSON_RPC__AUTH_STRATEGY"
+	ironicRPCTimeoutEnvVar           = "OS_JSON_RPC__TIMEOUT"
+	ironicRPCPortEnvVar              = "OS_JSON_RPC__PORT"
+	ironicRaidInterfaceEnvVar        = "OS_DEFAULT__DEFAULT_RAID_INTERFACE"
+	ironicCleanStepPriorityEnvVar    = "OS_CONDUCTOR__CLEAN_STEP_PRIORITY_OVERRIDE"
 )
 
 var podTemplateAnnotations = map[string]string{
@@ -79,9 +108,111 @@ var podTemplateAnnotations = map[string]string{
 var deploymentRolloutStartTime = time.Now()
 var deploymentRolloutTimeout = 5 * time.Minute
 
+// With the Recreate strategy, the new metal3 pod is only started once the old
+// one is gone. Past its deadline, an old pod still terminating is reported
+// as blocking the rollout.
+var podTerminationStuckThreshold = 5 * time.Minute
+
+// Database migrations on the first start of Ironic can take a while, leave
+// it twice the rollout timeout before considering it failed.
+var ironicStartupTimeout = 2 * deploymentRolloutTimeout
+
+const ironicStartupProbePeriod = 10 * time.Second
+
+func newIronicStartupProbe(info *ProvisioningInfo) *corev1.Probe {
+	ironicPort, _ := getControlPlanePorts(info)
+	return newProbe(corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sh", "-c", fmt.Sprintf("curl -sSfk https://127.0.0.1:%d", ironicPort)},
+			},
+		},
+		PeriodSeconds:    int32(ironicStartupProbePeriod.Seconds()),
+		TimeoutSeconds:   int32(ironicStartupProbePeriod.Seconds()),
+		FailureThreshold: int32(ironicStartupTimeout / ironicStartupProbePeriod),
+	}, info.ProvConfig.Spec.ProbeTuning)
+}
+
+// newProbe returns the given probe with its timing overridden by the
+// ProbeTuning of the Provisioning CR. All the probes of the metal3 containers
+// must be built through it.
+func newProbe(probe corev1.Probe, tuning *metal3iov1alpha1.ProbeTuning) *corev1.Probe {
+	if tuning == nil {
+		return &probe
+	}
+	if tuning.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *tuning.InitialDelaySeconds
+	}
+	if tuning.PeriodSeconds != nil {
+		probe.PeriodSeconds = *tuning.PeriodSeconds
+	}
+	if tuning.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *tuning.TimeoutSeconds
+	}
+	if tuning.FailureThreshold != nil {
+		probe.FailureThreshold = *tuning.FailureThreshold
+	}
+	return &probe
+}
+
+const defaultSharedVolumePath = "/shared"
+
 var sharedVolumeMount = corev1.VolumeMount{
 	Name:      baremetalSharedVolume,
-	MountPath: "/shared",
+	MountPath: defaultSharedVolumePath,
+}
+
+func getSharedVolumePath(config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.SharedVolumePath != "" {
+		return config.SharedVolumePath
+	}
+	return defaultSharedVolumePath
+}
+
+// relocateSharedPath moves p from the default shared volume path to
+// sharedPath, if it is under the former.
+func relocateSharedPath(p, sharedPath string) string {
+	if p == defaultSharedVolumePath {
+		return sharedPath
+	}
+	if strings.HasPrefix(p, defaultSharedVolumePath+"/") {
+		return sharedPath + strings.TrimPrefix(p, defaultSharedVolumePath)
+	}
+	return p
+}
+
+const (
+	ironicTokenVolume            = "ironic-token"
+	ironicTokenMountPath         = "/var/run/secrets/ironic"
+	ironicTokenExpirationSeconds = 3600
+	additionalTrustBundleFile    = "additional-ca-bundle.pem"
+)
+
+// proxyExemptContainers never make outbound HTTP requests, so they only get the
+// trusted CA bundle injected and not the proxy variables, which confuse their
+// startup scripts.
+var proxyExemptContainers = map[string]bool{
+	ramdiskLogsContainerName: true,
+}
+
+// The log watcher only reads the shared volume, except for the ramdisk log
+// directories where it removes each bundle once printed.
+var ramdiskLogsVolumeMounts = []corev1.VolumeMount{
+	{
+		Name:      baremetalSharedVolume,
+		MountPath: defaultSharedVolumePath,
+		ReadOnly:  true,
+	},
+	{
+		Name:      baremetalSharedVolume,
+		MountPath: "/shared/log/ironic/deploy",
+		SubPath:   "log/ironic/deploy",
+	},
+	{
+		Name:      baremetalSharedVolume,
+		MountPath: "/shared/log/ironic-inspector/ramdisk",
+		SubPath:   "log/ironic-inspector/ramdisk",
+	},
 }
 
 var ironicCredentialsMount = corev1.VolumeMount{
@@ -128,7 +259,7 @@ var pullSecret = corev1.EnvVar{
 
 func trustedCAVolume() corev1.Volume {
 	return corev1.Volume{
-		Name: "trusted-ca",
+		Name: trustedCAVolumeName,
 		VolumeSource: corev1.VolumeSource{
 			ConfigMap: &corev1.ConfigMapVolumeSource{
 				Items: []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}},
@@ -141,6 +272,68 @@ func trustedCAVolume() corev1.Volume {
 	}
 }
 
+// newTrustedCAVolume returns the trusted CA volume, which also carries the
+// AdditionalTrustBundleConfigMap bundle next to the operator-managed one when
+// it is set. Both ConfigMaps are optional.
+func newTrustedCAVolume(config *metal3iov1alpha1.ProvisioningSpec) corev1.Volume {
+	if config.AdditionalTrustBundleConfigMap == "" {
+		return trustedCAVolume()
+	}
+	return corev1.Volume{
+		Name: trustedCAVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						ConfigMap: &corev1.ConfigMapProjection{
+							Items: []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}},
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: externalTrustBundleConfigMapName,
+							},
+							Optional: pointer.BoolPtr(true),
+						},
+					},
+					{
+						ConfigMap: &corev1.ConfigMapProjection{
+							Items: []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: additionalTrustBundleFile}},
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: config.AdditionalTrustBundleConfigMap,
+							},
+							Optional: pointer.BoolPtr(true),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// withTrustedCAVolume returns a copy of volumes with the trusted CA volume
+// built from config.
+func withTrustedCAVolume(volumes []corev1.Volume, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Volume {
+	volumes = append([]corev1.Volume{}, volumes...)
+	for i := range volumes {
+		if volumes[i].Name == trustedCAVolumeName {
+			volumes[i] = newTrustedCAVolume(config)
+		}
+	}
+	return volumes
+}
+
+// withTLSSecret points the volumes of the CBO-managed TLS secret to the
+// external TLS secret of the Provisioning CR, when one is set.
+func withTLSSecret(volumes []corev1.Volume, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Volume {
+	volumes = append([]corev1.Volume{}, volumes...)
+	for i := range volumes {
+		if volumes[i].Secret != nil && volumes[i].Secret.SecretName == tlsSecretName {
+			source := *volumes[i].Secret
+			source.SecretName = getTlsSecretName(config)
+			volumes[i].VolumeSource = corev1.VolumeSource{Secret: &source}
+		}
+	}
+	return volumes
+}
+
 var metal3Volumes = []corev1.Volume{
 	{
 		Name: baremetalSharedVolume,
@@ -217,16 +410,12 @@ func buildEnvVar(name string, baremetalProvisioningConfig *metal3iov1alpha1.Prov
 			Name:  name,
 			Value: *value,
 		}
-	} else if name == provisioningIP && baremetalProvisioningConfig.ProvisioningNetwork == metal3iov1alpha1.ProvisioningNetworkDisabled {
-		return corev1.EnvVar{
-			Name: name,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath: "status.hostIP",
-				},
-			},
-		}
+	} else if name == provisioningIP && baremetalProvisioningConfig.ProvisioningNetwork == metal3iov1alpha1.ProvisioningNetworkDisabled &&
+		baremetalProvisioningConfig.ProvisioningIPSource != metal3iov1alpha1.ProvisioningIPSourceInterface {
+		return hostIPEnvVar(name)
 	}
+	// With the Interface source, an empty PROVISIONING_IP makes the Ironic
+	// image use the address of PROVISIONING_INTERFACE
 
 	return corev1.EnvVar{
 		Name: name,
@@ -235,8 +424,73 @@ func buildEnvVar(name string, baremetalProvisioningConfig *metal3iov1alpha1.Prov
 
 func getKernelParams(config *metal3iov1alpha1.ProvisioningSpec, networkStack NetworkStackType) string {
 	// OCPBUGS-872: workaround for https://bugzilla.redhat.com/show_bug.cgi?id=2111675
-	return fmt.Sprintf("rd.net.timeout.carrier=30 %s",
-		IpOptionForProvisioning(config, networkStack))
+	params := []string{"rd.net.timeout.carrier=30", IpOptionForProvisioning(config, networkStack)}
+	// Appended last so that they take precedence over the defaults
+	params = append(params, config.RamdiskExtraKernelParams...)
+	return strings.Join(params, " ")
+}
+
+// cleanStepPriorityOverride enables the given clean steps in the automated
+// cleaning, in the interface.step:priority format of Ironic. Higher
+// priorities run first, so the steps keep their order.
+func cleanStepPriorityOverride(steps []string) string {
+	overrides := make([]string, 0, len(steps))
+	for i, step := range steps {
+		overrides = append(overrides, fmt.Sprintf("%s:%d", step, len(steps)-i))
+	}
+	return strings.Join(overrides, ",")
+}
+
+// interfaceMTUEnvVars sets the provisioning interface MTU for the
+// containers configuring the interface or serving on it.
+func interfaceMTUEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	if config.ProvisioningInterfaceMTU == 0 {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  provisioningInterfaceMTU,
+			Value: fmt.Sprint(config.ProvisioningInterfaceMTU),
+		},
+	}
+}
+
+// logLevelEnvVars translates the requested log level and format into the
+// environment understood by the Ironic image, keeping all Ironic containers
+// consistent. The other metal3 containers do not run oslo services and must
+// not be given these.
+func logLevelEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if config.LogLevel != "" {
+		env = append(env,
+			corev1.EnvVar{
+				Name:  ironicLogLevelEnvVar,
+				Value: strings.ToUpper(string(config.LogLevel)),
+			},
+			corev1.EnvVar{
+				Name:  ironicDebugEnvVar,
+				Value: strconv.FormatBool(config.LogLevel == metal3iov1alpha1.LogLevelDebug),
+			})
+	}
+	if config.LogFormat == metal3iov1alpha1.LogFormatJSON {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicUseJSONEnvVar,
+			Value: "true",
+		})
+	}
+	return env
+}
+
+func softwareRAIDEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	if !config.EnableSoftwareRAIDRoot {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  ironicRaidInterfaceEnvVar,
+			Value: "agent",
+		},
+	}
 }
 
 func setIronicHtpasswdHash(name string, secretName string) corev1.EnvVar {
@@ -253,16 +507,22 @@ func setIronicHtpasswdHash(name string, secretName string) corev1.EnvVar {
 	}
 }
 
+func hostIPEnvVar(name string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: "status.hostIP",
+			},
+		},
+	}
+}
+
+// setIronicExternalIp is left empty with a Disabled provisioning network, so
+// that Ironic advertises the provisioning IP, whatever its source.
 func setIronicExternalIp(name string, config *metal3iov1alpha1.ProvisioningSpec) corev1.EnvVar {
 	if config.ProvisioningNetwork != metal3iov1alpha1.ProvisioningNetworkDisabled && config.VirtualMediaViaExternalNetwork {
-		return corev1.EnvVar{
-			Name: name,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					FieldPath: "status.hostIP",
-				},
-			},
-		}
+		return hostIPEnvVar(name)
 	}
 	return corev1.EnvVar{
 		Name: name,
@@ -290,25 +550,31 @@ func setIronicExternalUrl(info *ProvisioningInfo) (corev1.EnvVar, error) {
 		}, nil
 	}
 
-	// protocol, host, port
-	urlTemplate := "%s://[%s]:%s"
-
 	if info.ProvConfig.Spec.DisableVirtualMediaTLS {
 		return corev1.EnvVar{
 			Name:  externalUrlEnvVar,
-			Value: fmt.Sprintf(urlTemplate, "http", ironicIPv6, baremetalHttpPort),
+			Value: "http://" + net.JoinHostPort(ironicIPv6, getHttpPort(&info.ProvConfig.Spec)),
 		}, nil
 	} else {
 		return corev1.EnvVar{
 			Name:  externalUrlEnvVar,
-			Value: fmt.Sprintf(urlTemplate, "https", ironicIPv6, baremetalVmediaHttpsPort),
+			Value: "https://" + net.JoinHostPort(ironicIPv6, baremetalVmediaHttpsPort),
 		}, nil
 	}
 }
 
+// newMetal3InitContainers returns the init containers of the metal3 pod. Unlike
+// the containers they run one after the other, so they are kept in the order
+// they are appended in rather than sorted.
 func newMetal3InitContainers(info *ProvisioningInfo) []corev1.Container {
 	initContainers := []corev1.Container{}
 
+	// Check the provisioning interface first, before any other container
+	// depends on it
+	if info.ProvConfig.Spec.CheckProvisioningInterface && info.ProvConfig.Spec.ProvisioningNetwork != metal3iov1alpha1.ProvisioningNetworkDisabled {
+		initContainers = append(initContainers, createInitContainerProvisioningInterfaceCheck(info.Images, &info.ProvConfig.Spec))
+	}
+
 	// If the provisioning network is disabled, and the user hasn't requested a
 	// particular provisioning IP on the machine CIDR, we have nothing for this container
 	// to manage.
@@ -324,12 +590,69 @@ func newMetal3InitContainers(info *ProvisioningInfo) []corev1.Container {
 		initContainers = append(initContainers, createInitContainerMachineOsDownloader(info, info.ProvConfig.Spec.ProvisioningOSDownloadURL, false, true))
 	}
 
-	return injectProxyAndCA(initContainers, info.Proxy)
+	// Pulling the Ironic image last caches it before the main containers start
+	if info.ProvConfig.Spec.PrePullIronicImage {
+		initContainers = append(initContainers, createInitContainerIronicPrePull(info.Images))
+	}
+
+	initContainers = injectProxyAndCA(initContainers, info.Proxy, &info.ProvConfig.Spec)
+	initContainers = withSharedVolumePath(initContainers, &info.ProvConfig.Spec)
+	initContainers = withInitContainerEnv(withDownloadProxy(initContainers, &info.ProvConfig.Spec), &info.ProvConfig.Spec)
+	initContainers = withImageOverrides(withImagePullPolicy(initContainers, &info.ProvConfig.Spec), &info.ProvConfig.Spec)
+	return withResourceRequests(initContainers, &info.ProvConfig.Spec)
+}
+
+// withInitContainerEnv appends the environment requested in the Provisioning
+// CR to the matching init containers, skipping the variables already set.
+func withInitContainerEnv(initContainers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	for i := range initContainers {
+		for _, extra := range config.InitContainerEnv[initContainers[i].Name] {
+			exists := false
+			for _, env := range initContainers[i].Env {
+				if env.Name == extra.Name {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				initContainers[i].Env = append(initContainers[i].Env, extra)
+			}
+		}
+	}
+	return initContainers
+}
+
+// withDownloadProxy replaces the proxy of the machine OS downloader init
+// containers with the DownloadProxy of the config, when set. It is applied
+// after injectProxyAndCA so that NO_PROXY is kept.
+func withDownloadProxy(initContainers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	if config.DownloadProxy == nil {
+		return initContainers
+	}
+	for i := range initContainers {
+		if !strings.HasPrefix(initContainers[i].Name, machineOsDownloaderContainerName) {
+			continue
+		}
+		env := []corev1.EnvVar{}
+		for _, envVar := range initContainers[i].Env {
+			if envVar.Name != "HTTP_PROXY" && envVar.Name != "HTTPS_PROXY" {
+				env = append(env, envVar)
+			}
+		}
+		if config.DownloadProxy.HTTPProxy != "" {
+			env = append(env, corev1.EnvVar{Name: "HTTP_PROXY", Value: config.DownloadProxy.HTTPProxy})
+		}
+		if config.DownloadProxy.HTTPSProxy != "" {
+			env = append(env, corev1.EnvVar{Name: "HTTPS_PROXY", Value: config.DownloadProxy.HTTPSProxy})
+		}
+		initContainers[i].Env = env
+	}
+	return initContainers
 }
 
 func createInitContainerMachineOsDownloader(info *ProvisioningInfo, imageURLs string, useLiveImages, setIpOptions bool) corev1.Container {
 	var command string
-	name := "metal3-machine-os-downloader"
+	name := machineOsDownloaderContainerName
 	if useLiveImages {
 		command = "/usr/local/bin/get-live-images.sh"
 		name = name + "-live-images"
@@ -382,11 +705,11 @@ func createInitContainerStaticIpSet(images *Images, config *metal3iov1alpha1.Pro
 				Add: []corev1.Capability{"NET_ADMIN"},
 			},
 		},
-		Env: []corev1.EnvVar{
+		Env: append([]corev1.EnvVar{
 			buildEnvVar(provisioningIP, config),
 			buildEnvVar(provisioningInterface, config),
 			buildEnvVar(provisioningMacAddresses, config),
-		},
+		}, interfaceMTUEnvVars(config)...),
 		Resources: corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
 				corev1.ResourceCPU:    resource.MustParse("10m"),
@@ -398,11 +721,85 @@ func createInitContainerStaticIpSet(images *Images, config *metal3iov1alpha1.Pro
 	return initContainer
 }
 
+// provisioningInterfaceCheckScript fails when the provisioning interface is
+// missing on the host. The interface may be left empty in favour of the
+// provisioning MAC addresses, which are not checked.
+const provisioningInterfaceCheckScript = `if [ -n "$PROVISIONING_INTERFACE" ] && [ ! -e "/sys/class/net/$PROVISIONING_INTERFACE" ]; then
+  echo "provisioning interface $PROVISIONING_INTERFACE does not exist on this host" >&2
+  exit 1
+fi`
+
+func createInitContainerProvisioningInterfaceCheck(images *Images, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
+	initContainer := corev1.Container{
+		Name:            "metal3-provisioning-interface-check",
+		Image:           images.Ironic,
+		Command:         []string{"/bin/bash", "-c", provisioningInterfaceCheckScript},
+		ImagePullPolicy: "IfNotPresent",
+		SecurityContext: withoutCapabilities(config),
+		Env: []corev1.EnvVar{
+			buildEnvVar(provisioningInterface, config),
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+		},
+	}
+
+	return initContainer
+}
+
+func createInitContainerIronicPrePull(images *Images) corev1.Container {
+	initContainer := corev1.Container{
+		Name:            "metal3-ironic-pre-pull",
+		Image:           images.Ironic,
+		Command:         []string{"/bin/true"},
+		ImagePullPolicy: "IfNotPresent",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+		},
+	}
+
+	return initContainer
+}
+
 func newMetal3Containers(info *ProvisioningInfo) []corev1.Container {
+	containers, _ := partitionMetal3Containers(newAllMetal3Containers(info), &info.ProvConfig.Spec)
+	return containers
+}
+
+// partitionMetal3Containers splits the containers between the metal3 pod and,
+// with SplitDeployment, the inspector pod. Both pods get a log watcher, as
+// each one only sees the ramdisk logs written to its own shared volume.
+func partitionMetal3Containers(containers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) (metal3 []corev1.Container, inspector []corev1.Container) {
+	if !UseSplitDeployment(config) {
+		return containers, nil
+	}
+	for _, container := range containers {
+		switch container.Name {
+		case inspectorContainerName:
+			inspector = append(inspector, container)
+		case ramdiskLogsContainerName:
+			metal3 = append(metal3, container)
+			inspector = append(inspector, *container.DeepCopy())
+		default:
+			metal3 = append(metal3, container)
+		}
+	}
+	return metal3, inspector
+}
+
+// newAllMetal3Containers returns the containers of all the metal3 components,
+// before they are split between pods.
+func newAllMetal3Containers(info *ProvisioningInfo) []corev1.Container {
 	containers := []corev1.Container{
 		createContainerMetal3Httpd(info.Images, &info.ProvConfig.Spec, info.SSHKey),
 		createContainerMetal3Ironic(info.Images, info, &info.ProvConfig.Spec, info.SSHKey),
-		createContainerMetal3RamdiskLogs(info.Images),
+		createContainerMetal3RamdiskLogs(info.Images, &info.ProvConfig.Spec),
 		createContainerMetal3IronicInspector(info.Images, info, &info.ProvConfig.Spec),
 	}
 
@@ -413,15 +810,129 @@ func newMetal3Containers(info *ProvisioningInfo) []corev1.Container {
 		containers = append(containers, createContainerMetal3StaticIpManager(info.Images, &info.ProvConfig.Spec))
 	}
 
-	if info.ProvConfig.Spec.ProvisioningNetwork != metal3iov1alpha1.ProvisioningNetworkDisabled {
-		containers = append(containers, createContainerMetal3Dnsmasq(info.Images, &info.ProvConfig.Spec))
+	if info.ProvConfig.Spec.ProvisioningVIP != "" && info.ProvConfig.Spec.ProvisioningNetwork != metal3iov1alpha1.ProvisioningNetworkDisabled {
+		containers = append(containers, createContainerMetal3Keepalived(info.Images, &info.ProvConfig.Spec))
 	}
 
-	return injectProxyAndCA(containers, info.Proxy)
+	// With an external DHCP server, nothing is left for dnsmasq to serve.
+	if info.ProvConfig.Spec.ProvisioningNetwork != metal3iov1alpha1.ProvisioningNetworkDisabled && !info.ProvConfig.Spec.ExternalDHCP {
+		containers = append(containers, createContainerMetal3Dnsmasq(info.Images, &info.ProvConfig.Spec, info.NetworkStack))
+	}
+
+	containers = withImagePullPolicy(injectProxyAndCA(containers, info.Proxy, &info.ProvConfig.Spec), &info.ProvConfig.Spec)
+	containers = withImageOverrides(withSharedVolumePath(containers, &info.ProvConfig.Spec), &info.ProvConfig.Spec)
+	containers = withExtraHostPathMounts(containers, &info.ProvConfig.Spec)
+	containers = withResourceRequests(withoutHostPorts(containers, &info.ProvConfig.Spec), &info.ProvConfig.Spec)
+
+	// The containers are appended conditionally, sorting them keeps the
+	// order stable across configurations and avoids spurious updates.
+	sort.SliceStable(containers, func(i, j int) bool {
+		return containers[i].Name < containers[j].Name
+	})
+	return containers
+}
+
+// withResourceRequests merges the resource requests set in the Provisioning
+// CR for each container over its defaults.
+func withResourceRequests(containers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	for i := range containers {
+		overrides, ok := config.ResourceRequests[containers[i].Name]
+		if !ok {
+			continue
+		}
+		requests := corev1.ResourceList{}
+		for name, quantity := range containers[i].Resources.Requests {
+			requests[name] = quantity
+		}
+		for name, quantity := range overrides {
+			requests[name] = quantity.DeepCopy()
+		}
+		containers[i].Resources.Requests = requests
+	}
+	return containers
+}
+
+// withoutHostPorts drops the hostPort of all the container ports when
+// requested in the Provisioning CR. Only meant for host networked pods, where
+// the container ports are already bound on the host.
+func withoutHostPorts(containers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	if !config.DisableHostPorts {
+		return containers
+	}
+	for i := range containers {
+		ports := make([]corev1.ContainerPort, len(containers[i].Ports))
+		for j, port := range containers[i].Ports {
+			port.HostPort = 0
+			ports[j] = port
+		}
+		containers[i].Ports = ports
+	}
+	return containers
+}
+
+// withSharedVolumePath moves the mounts under the default shared volume path,
+// as well as the environment and command paths referencing them, to the
+// SharedVolumePath of the Provisioning CR. The mounts are copied since the
+// defaults are shared between containers.
+func withSharedVolumePath(containers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	sharedPath := getSharedVolumePath(config)
+	if sharedPath == defaultSharedVolumePath {
+		return containers
+	}
+	for i := range containers {
+		mounts := make([]corev1.VolumeMount, len(containers[i].VolumeMounts))
+		for j, mount := range containers[i].VolumeMounts {
+			mount.MountPath = relocateSharedPath(mount.MountPath, sharedPath)
+			mounts[j] = mount
+		}
+		containers[i].VolumeMounts = mounts
+
+		env := make([]corev1.EnvVar, len(containers[i].Env))
+		for j, envVar := range containers[i].Env {
+			envVar.Value = relocateSharedPath(envVar.Value, sharedPath)
+			env[j] = envVar
+		}
+		containers[i].Env = env
+
+		command := make([]string, len(containers[i].Command))
+		for j, arg := range containers[i].Command {
+			command[j] = relocateSharedPath(arg, sharedPath)
+		}
+		containers[i].Command = command
+	}
+	return containers
+}
+
+// withImageOverrides replaces the image of the containers listed in the
+// ImageOverrides of the Provisioning CR.
+func withImageOverrides(containers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	for i := range containers {
+		if image, ok := config.ImageOverrides[containers[i].Name]; ok {
+			containers[i].Image = image
+		}
+	}
+	return containers
+}
+
+// withImagePullPolicy applies the pull policy requested in the Provisioning CR,
+// if any, to all the given containers.
+func withImagePullPolicy(containers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	if config.ImagePullPolicy == "" {
+		return containers
+	}
+	for i := range containers {
+		containers[i].ImagePullPolicy = config.ImagePullPolicy
+	}
+	return containers
 }
 
 func getWatchNamespace(config *metal3iov1alpha1.ProvisioningSpec) corev1.EnvVar {
-	if config.WatchAllNamespaces {
+	if len(config.WatchNamespaces) > 0 {
+		return corev1.EnvVar{
+			Name:  "WATCH_NAMESPACE",
+			Value: strings.Join(config.WatchNamespaces, ","),
+		}
+	} else if config.WatchAllNamespaces {
 		return corev1.EnvVar{
 			Name:  "WATCH_NAMESPACE",
 			Value: "",
@@ -442,7 +953,18 @@ func buildSSHKeyEnvVar(sshKey string) corev1.EnvVar {
 	return corev1.EnvVar{Name: sshKeyEnvVar, Value: sshKey}
 }
 
-func createContainerMetal3Dnsmasq(images *Images, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
+// dnsmasqServesIPv6 reports whether dnsmasq serves an IPv6 DHCP range. The
+// provisioning network has a single IP family, even on dual-stack clusters,
+// so the cluster network stack only matters when its CIDR is unknown.
+func dnsmasqServesIPv6(config *metal3iov1alpha1.ProvisioningSpec, networkStack NetworkStackType) bool {
+	_, cidr, err := net.ParseCIDR(config.ProvisioningNetworkCIDR)
+	if err != nil {
+		return networkStack == NetworkStackV6
+	}
+	return cidr.IP.To4() == nil
+}
+
+func createContainerMetal3Dnsmasq(images *Images, config *metal3iov1alpha1.ProvisioningSpec, networkStack NetworkStackType) corev1.Container {
 	envVars := []corev1.EnvVar{
 		buildEnvVar(httpPort, config),
 		buildEnvVar(provisioningInterface, config),
@@ -455,6 +977,28 @@ func createContainerMetal3Dnsmasq(images *Images, config *metal3iov1alpha1.Provi
 			Value: useProvisioningDNS,
 		})
 	}
+	if config.DHCPLeaseTime != nil {
+		// dnsmasq takes the lease time in seconds
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  dhcpLeaseTime,
+			Value: strconv.Itoa(int(config.DHCPLeaseTime.Seconds())),
+		})
+	}
+	// IPv4 is the dnsmasq default, IPv6 additionally needs DHCPv6 and
+	// router advertisements for the SLAAC hints.
+	if dnsmasqServesIPv6(config, networkStack) {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: dnsmasqIPStack, Value: "v6"},
+			corev1.EnvVar{Name: dnsmasqEnableRA, Value: "true"},
+		)
+	}
+	if config.TFTPBlockSize != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  tftpBlockSize,
+			Value: fmt.Sprint(*config.TFTPBlockSize),
+		})
+	}
+	envVars = append(envVars, interfaceMTUEnvVars(config)...)
 	container := corev1.Container{
 		Name:            "metal3-dnsmasq",
 		Image:           images.Ironic,
@@ -481,14 +1025,14 @@ func createContainerMetal3Dnsmasq(images *Images, config *metal3iov1alpha1.Provi
 }
 
 func createContainerMetal3Httpd(images *Images, config *metal3iov1alpha1.ProvisioningSpec, sshKey string) corev1.Container {
-	port, _ := strconv.Atoi(baremetalHttpPort)             // #nosec
+	port, _ := strconv.Atoi(getHttpPort(config))           // #nosec
 	httpsPort, _ := strconv.Atoi(baremetalVmediaHttpsPort) // #nosec
 
-	ironicPort := baremetalIronicPort
-	inspectorPort := baremetalIronicInspectorPort
+	ironicPort := getIronicPort(config)
+	inspectorPort := getInspectorPort(config)
 	// In the proxy mode, the ironic API is served on the private port,
 	// while ironic-proxy, running as a DeamonSet on all nodes, serves on
-	// 6385 and proxies the traffic (same for inspector).
+	// the public port and proxies the traffic (same for inspector).
 	if UseIronicProxy(config) {
 		ironicPort = ironicPrivatePort
 		inspectorPort = inspectorPrivatePort
@@ -508,17 +1052,20 @@ func createContainerMetal3Httpd(images *Images, config *metal3iov1alpha1.Provisi
 			ContainerPort: int32(ironicPort),
 			HostPort:      int32(ironicPort),
 		},
-		{
+	}
+	// With SplitDeployment, inspector serves its port itself from its own pod
+	if !UseSplitDeployment(config) {
+		ports = append(ports, corev1.ContainerPort{
 			Name:          "inspector",
 			ContainerPort: int32(inspectorPort),
 			HostPort:      int32(inspectorPort),
-		},
-		{
-			Name:          httpPortName,
-			ContainerPort: int32(port),
-			HostPort:      int32(port),
-		},
+		})
 	}
+	ports = append(ports, corev1.ContainerPort{
+		Name:          httpPortName,
+		ContainerPort: int32(port),
+		HostPort:      int32(port),
+	})
 
 	if !config.DisableVirtualMediaTLS {
 		volumes = append(volumes, vmediaTlsMount)
@@ -554,7 +1101,7 @@ func createContainerMetal3Httpd(images *Images, config *metal3iov1alpha1.Provisi
 			},
 			{
 				Name:  inspectorProxyEnvVar,
-				Value: "true",
+				Value: strconv.FormatBool(!UseSplitDeployment(config)),
 			},
 			{
 				Name:  ironicPrivatePortEnvVar,
@@ -590,6 +1137,53 @@ func createContainerMetal3Httpd(images *Images, config *metal3iov1alpha1.Provisi
 	return container
 }
 
+// internalTLSEnvVars points the clients of the Ironic API, and of the
+// Inspector API if withInspector is set, at the mounted certificates when
+// InternalTLS is enabled.
+func internalTLSEnvVars(config *metal3iov1alpha1.ProvisioningSpec, withInspector bool) []corev1.EnvVar {
+	if !config.InternalTLS {
+		return nil
+	}
+	env := []corev1.EnvVar{
+		{
+			Name:  ironicCertEnvVar,
+			Value: metal3TlsRootDir + "/ironic/" + corev1.TLSCertKey,
+		},
+	}
+	if withInspector {
+		env = append(env, corev1.EnvVar{
+			Name:  inspectorCertEnvVar,
+			Value: metal3TlsRootDir + "/ironic-inspector/" + corev1.TLSCertKey,
+		})
+	}
+	return env
+}
+
+// rpcEnvVars configures the JSON-RPC transport between the Ironic API and
+// conductor, which both run in the Ironic container.
+func rpcEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if config.RPCAuthStrategy != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicRPCAuthStrategyEnvVar,
+			Value: string(config.RPCAuthStrategy),
+		})
+	}
+	if config.RPCTimeout != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicRPCTimeoutEnvVar,
+			Value: strconv.Itoa(int(config.RPCTimeout.Seconds())),
+		})
+	}
+	if config.JSONRPCPort != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicRPCPortEnvVar,
+			Value: strconv.Itoa(int(*config.JSONRPCPort)),
+		})
+	}
+	return env
+}
+
 func createContainerMetal3Ironic(images *Images, info *ProvisioningInfo, config *metal3iov1alpha1.ProvisioningSpec, sshKey string) corev1.Container {
 	volumes := []corev1.VolumeMount{
 		sharedVolumeMount,
@@ -601,9 +1195,111 @@ func createContainerMetal3Ironic(images *Images, info *ProvisioningInfo, config
 	if !config.DisableVirtualMediaTLS {
 		volumes = append(volumes, vmediaTlsMount)
 	}
+	if config.ServiceAccountTokenAudience != "" {
+		volumes = append(volumes, corev1.VolumeMount{
+			Name:      ironicTokenVolume,
+			MountPath: ironicTokenMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	env := []corev1.EnvVar{
+		{
+			Name:  ironicInsecureEnvVar,
+			Value: strconv.FormatBool(!config.InternalTLS),
+		},
+		{
+			Name:  inspectorInsecureEnvVar,
+			Value: strconv.FormatBool(!config.InternalTLS),
+		},
+		{
+			Name:  ironicKernelParamsEnvVar,
+			Value: getKernelParams(&info.ProvConfig.Spec, info.NetworkStack),
+		},
+		{
+			Name:  ironicProxyEnvVar,
+			Value: "true",
+		},
+		{
+			Name:  ironicPrivatePortEnvVar,
+			Value: useUnixSocket,
+		},
+		buildEnvVar(httpPort, config),
+		buildEnvVar(provisioningIP, config),
+		buildEnvVar(provisioningInterface, config),
+		buildSSHKeyEnvVar(sshKey),
+		setIronicExternalIp(externalIpEnvVar, config),
+		buildEnvVar(provisioningMacAddresses, config),
+		buildEnvVar(vmediaHttpsPort, config),
+		// TODO(dtantsur): remove when removing inspector
+		{
+			Name:  forceInspectorEnvVar,
+			Value: "true",
+		},
+	}
+	if len(config.BMCPollingOverrides) > 0 {
+		env = append(env, buildEnvVar(bmcPollingOverrides, config))
+	}
+	if config.NetworkInterface != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicNetworkInterfaceEnvVar,
+			Value: string(config.NetworkInterface),
+		})
+	}
+	if config.EnableRetirementWorkflow {
+		// Retired nodes leave service through the automated cleaning
+		// run when they are torn down
+		env = append(env, corev1.EnvVar{
+			Name:  ironicAutomatedCleanEnvVar,
+			Value: "true",
+		})
+		if len(config.RetirementCleanSteps) > 0 {
+			env = append(env, corev1.EnvVar{
+				Name:  ironicCleanStepPriorityEnvVar,
+				Value: cleanStepPriorityOverride(config.RetirementCleanSteps),
+			})
+		}
+	}
+	if config.ImageDownloadTimeout != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicDownloadTimeoutEnvVar,
+			Value: strconv.Itoa(int(config.ImageDownloadTimeout.Seconds())),
+		})
+	}
+	if config.DeployTimeout != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicDeployTimeoutEnvVar,
+			Value: strconv.Itoa(int(config.DeployTimeout.Seconds())),
+		})
+	}
+	if config.PowerStateSyncInterval != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicPowerSyncIntervalEnvVar,
+			Value: strconv.Itoa(int(config.PowerStateSyncInterval.Seconds())),
+		})
+	}
+	env = append(env, softwareRAIDEnvVars(config)...)
+	env = append(env, internalTLSEnvVars(config, true)...)
+	env = append(env, rpcEnvVars(config)...)
+	if config.ConductorGroup != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicConductorGroupEnvVar,
+			Value: *config.ConductorGroup,
+		})
+	}
+	if config.ConductorConcurrency != 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  ironicMaxConcurrentDeployEnvVar,
+			Value: strconv.Itoa(int(config.ConductorConcurrency)),
+		})
+	}
+	env = append(env, logLevelEnvVars(config)...)
+	if getIronicCallbackURL(config) != nil {
+		env = append(env, buildEnvVar(ironicCallbackUrl, config))
+	}
 
 	container := corev1.Container{
-		Name:            "metal3-ironic",
+		Name:            ironicContainerName,
 		Image:           images.Ironic,
 		ImagePullPolicy: "IfNotPresent",
 		SecurityContext: &corev1.SecurityContext{
@@ -612,40 +1308,8 @@ func createContainerMetal3Ironic(images *Images, info *ProvisioningInfo, config
 		},
 		Command:      []string{"/bin/runironic"},
 		VolumeMounts: volumes,
-		Env: []corev1.EnvVar{
-			{
-				Name:  ironicInsecureEnvVar,
-				Value: "true",
-			},
-			{
-				Name:  inspectorInsecureEnvVar,
-				Value: "true",
-			},
-			{
-				Name:  ironicKernelParamsEnvVar,
-				Value: getKernelParams(&info.ProvConfig.Spec, info.NetworkStack),
-			},
-			{
-				Name:  ironicProxyEnvVar,
-				Value: "true",
-			},
-			{
-				Name:  ironicPrivatePortEnvVar,
-				Value: useUnixSocket,
-			},
-			buildEnvVar(httpPort, config),
-			buildEnvVar(provisioningIP, config),
-			buildEnvVar(provisioningInterface, config),
-			buildSSHKeyEnvVar(sshKey),
-			setIronicExternalIp(externalIpEnvVar, config),
-			buildEnvVar(provisioningMacAddresses, config),
-			buildEnvVar(vmediaHttpsPort, config),
-			// TODO(dtantsur): remove when removing inspector
-			{
-				Name:  forceInspectorEnvVar,
-				Value: "true",
-			},
-		},
+		Env:          env,
+		StartupProbe: newIronicStartupProbe(info),
 		Resources: corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
 				corev1.ResourceCPU:    resource.MustParse("50m"),
@@ -657,13 +1321,29 @@ func createContainerMetal3Ironic(images *Images, info *ProvisioningInfo, config
 	return container
 }
 
-func createContainerMetal3RamdiskLogs(images *Images) corev1.Container {
+// withoutCapabilities is the security context of the unprivileged containers,
+// which need none of the default Linux capabilities. With
+// HardenSecurityContext, they also cannot gain privileges.
+func withoutCapabilities(config *metal3iov1alpha1.ProvisioningSpec) *corev1.SecurityContext {
+	securityContext := &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+	if config.HardenSecurityContext {
+		securityContext.AllowPrivilegeEscalation = pointer.BoolPtr(false)
+	}
+	return securityContext
+}
+
+func createContainerMetal3RamdiskLogs(images *Images, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
 	container := corev1.Container{
-		Name:            "metal3-ramdisk-logs",
+		Name:            ramdiskLogsContainerName,
 		Image:           images.Ironic,
 		ImagePullPolicy: "IfNotPresent",
 		Command:         []string{"/bin/runlogwatch.sh"},
-		VolumeMounts:    []corev1.VolumeMount{sharedVolumeMount},
+		SecurityContext: withoutCapabilities(config),
+		VolumeMounts:    ramdiskLogsVolumeMounts,
 		Resources: corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
 				corev1.ResourceCPU:    resource.MustParse("10m"),
@@ -676,7 +1356,7 @@ func createContainerMetal3RamdiskLogs(images *Images) corev1.Container {
 
 func createContainerMetal3IronicInspector(images *Images, info *ProvisioningInfo, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
 	container := corev1.Container{
-		Name:            "metal3-ironic-inspector",
+		Name:            inspectorContainerName,
 		Image:           images.Ironic,
 		ImagePullPolicy: "IfNotPresent",
 		Command:         []string{"/bin/runironic-inspector"},
@@ -686,10 +1366,10 @@ func createContainerMetal3IronicInspector(images *Images, info *ProvisioningInfo
 			ironicTlsMount,
 			inspectorTlsMount,
 		},
-		Env: []corev1.EnvVar{
+		Env: append([]corev1.EnvVar{
 			{
 				Name:  ironicInsecureEnvVar,
-				Value: "true",
+				Value: strconv.FormatBool(!config.InternalTLS),
 			},
 			{
 				Name:  ironicKernelParamsEnvVar,
@@ -710,7 +1390,7 @@ func createContainerMetal3IronicInspector(images *Images, info *ProvisioningInfo
 				Name:  forceInspectorEnvVar,
 				Value: "true",
 			},
-		},
+		}, append(internalTLSEnvVars(config, false), logLevelEnvVars(config)...)...),
 		Resources: corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
 				corev1.ResourceCPU:    resource.MustParse("40m"),
@@ -718,6 +1398,15 @@ func createContainerMetal3IronicInspector(images *Images, info *ProvisioningInfo
 			},
 		},
 	}
+	if config.InspectionTimeout != nil {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  inspectorTimeoutEnvVar,
+			Value: strconv.Itoa(int(config.InspectionTimeout.Seconds())),
+		})
+	}
+	if UseSplitDeployment(config) {
+		container = withInspectorServingPort(container, config)
+	}
 
 	return container
 }
@@ -732,11 +1421,11 @@ func createContainerMetal3StaticIpManager(images *Images, config *metal3iov1alph
 			// Needed for mounting /proc to set the addr_gen_mode
 			Privileged: pointer.BoolPtr(true),
 		},
-		Env: []corev1.EnvVar{
+		Env: append([]corev1.EnvVar{
 			buildEnvVar(provisioningIP, config),
 			buildEnvVar(provisioningInterface, config),
 			buildEnvVar(provisioningMacAddresses, config),
-		},
+		}, interfaceMTUEnvVars(config)...),
 		Resources: corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
 				corev1.ResourceCPU:    resource.MustParse("5m"),
@@ -748,6 +1437,159 @@ func createContainerMetal3StaticIpManager(images *Images, config *metal3iov1alph
 	return container
 }
 
+// createContainerMetal3Keepalived advertises the provisioning VIP on the
+// provisioning interface while the metal3 httpd port is up, using the
+// environment of the OpenShift ipfailover image.
+func createContainerMetal3Keepalived(images *Images, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
+	container := corev1.Container{
+		Name:            "metal3-keepalived",
+		Image:           images.Keepalived,
+		ImagePullPolicy: "IfNotPresent",
+		SecurityContext: &corev1.SecurityContext{
+			// Needed for adding the VIP and sending VRRP advertisements
+			Privileged: pointer.BoolPtr(true),
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "OPENSHIFT_HA_CONFIG_NAME",
+				Value: "metal3-provisioning",
+			},
+			{
+				Name:  "OPENSHIFT_HA_VIRTUAL_IPS",
+				Value: config.ProvisioningVIP,
+			},
+			{
+				Name:  "OPENSHIFT_HA_NETWORK_INTERFACE",
+				Value: config.ProvisioningInterface,
+			},
+			{
+				Name:  "OPENSHIFT_HA_MONITOR_PORT",
+				Value: getHttpPort(config),
+			},
+			{
+				Name:  "OPENSHIFT_HA_REPLICA_COUNT",
+				Value: "1",
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+			},
+		},
+	}
+
+	return container
+}
+
+// appendTolerations appends the additional tolerations that are not already
+// in the list.
+func appendTolerations(tolerations []corev1.Toleration, additional []corev1.Toleration) []corev1.Toleration {
+	for _, toleration := range additional {
+		duplicate := false
+		for _, existing := range tolerations {
+			if equality.Semantic.DeepEqual(existing, toleration) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			tolerations = append(tolerations, toleration)
+		}
+	}
+	return tolerations
+}
+
+func getMetal3NodeSelector(info *ProvisioningInfo) map[string]string {
+	config := &info.ProvConfig.Spec
+	if len(config.NodeSelector) > 0 {
+		return config.NodeSelector
+	}
+	// On single-node clusters the only node may not carry the master role
+	// label, e.g. on edge variants relabelling it.
+	if config.Affinity != nil || info.SingleNodeTopology {
+		return nil
+	}
+	return map[string]string{"node-role.kubernetes.io/master": ""}
+}
+
+// newSharedVolumeSource returns the emptyDir backing the shared volume.
+func newSharedVolumeSource(config *metal3iov1alpha1.ProvisioningSpec) *corev1.EmptyDirVolumeSource {
+	source := &corev1.EmptyDirVolumeSource{
+		SizeLimit: config.SharedVolumeSizeLimit,
+	}
+	if config.SharedVolumeMedium == metal3iov1alpha1.SharedVolumeMediumMemory {
+		source.Medium = corev1.StorageMediumMemory
+	}
+	return source
+}
+
+// newMetal3Volumes returns the volumes of the metal3 pod, including the
+// projected service account token when an audience is requested.
+func newMetal3Volumes(config *metal3iov1alpha1.ProvisioningSpec) []corev1.Volume {
+	volumes := withTLSSecret(withTrustedCAVolume(metal3Volumes, config), config)
+	for i := range volumes {
+		if volumes[i].Name == baremetalSharedVolume {
+			volumes[i].VolumeSource = corev1.VolumeSource{
+				EmptyDir: newSharedVolumeSource(config),
+			}
+		}
+	}
+	if config.ServiceAccountTokenAudience != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: ironicTokenVolume,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+								Audience:          config.ServiceAccountTokenAudience,
+								ExpirationSeconds: pointer.Int64Ptr(ironicTokenExpirationSeconds),
+								Path:              "token",
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+	for _, extra := range config.ExtraHostPathVolumes {
+		volumes = append(volumes, corev1.Volume{
+			Name: extraHostPathVolumeName(extra),
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: extra.HostPath,
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+// extraHostPathVolumeName prefixes the name of the extra host path volumes so
+// that they cannot collide with the metal3 volumes.
+func extraHostPathVolumeName(extra metal3iov1alpha1.HostPathVolume) string {
+	return "host-" + extra.Name
+}
+
+// withExtraHostPathMounts mounts the extra host path volumes of the
+// Provisioning CR read-only into the requested containers.
+func withExtraHostPathMounts(containers []corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
+	for _, extra := range config.ExtraHostPathVolumes {
+		for i := range containers {
+			if !slices.Contains(extra.Containers, containers[i].Name) {
+				continue
+			}
+			containers[i].VolumeMounts = append(containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      extraHostPathVolumeName(extra),
+				MountPath: extra.MountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+	return containers
+}
+
 func newMetal3PodTemplateSpec(info *ProvisioningInfo, labels *map[string]string) *corev1.PodTemplateSpec {
 	initContainers := newMetal3InitContainers(info)
 	containers := newMetal3Containers(info)
@@ -774,6 +1616,7 @@ func newMetal3PodTemplateSpec(info *ProvisioningInfo, labels *map[string]string)
 			TolerationSeconds: pointer.Int64Ptr(120),
 		},
 	}
+	tolerations = appendTolerations(tolerations, info.ProvConfig.Spec.AdditionalTolerations)
 
 	return &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -781,37 +1624,67 @@ func newMetal3PodTemplateSpec(info *ProvisioningInfo, labels *map[string]string)
 			Labels:      *labels,
 		},
 		Spec: corev1.PodSpec{
-			Volumes:           metal3Volumes,
-			InitContainers:    initContainers,
-			Containers:        containers,
-			HostNetwork:       true,
-			DNSPolicy:         corev1.DNSClusterFirstWithHostNet,
-			PriorityClassName: "system-node-critical",
-			NodeSelector:      map[string]string{"node-role.kubernetes.io/master": ""},
-			SecurityContext: &corev1.PodSecurityContext{
-				RunAsNonRoot: pointer.BoolPtr(false),
-			},
-			ServiceAccountName: "cluster-baremetal-operator",
+			Volumes:            newMetal3Volumes(&info.ProvConfig.Spec),
+			InitContainers:     initContainers,
+			Containers:         containers,
+			HostNetwork:        true,
+			HostPID:            info.ProvConfig.Spec.HostPID,
+			DNSPolicy:          getMetal3DNSPolicy(&info.ProvConfig.Spec),
+			DNSConfig:          info.ProvConfig.Spec.DNSConfig.DeepCopy(),
+			PriorityClassName:  getMetal3PriorityClassName(&info.ProvConfig.Spec),
+			NodeSelector:       getMetal3NodeSelector(info),
+			Affinity:           info.ProvConfig.Spec.Affinity.DeepCopy(),
+			SecurityContext:    newMetal3PodSecurityContext(&info.ProvConfig.Spec),
+			ServiceAccountName: getMetal3ServiceAccountName(&info.ProvConfig.Spec),
 			Tolerations:        tolerations,
+			ImagePullSecrets:   newImagePullSecrets(&info.ProvConfig.Spec),
+
+			TerminationGracePeriodSeconds: getMetal3TerminationGracePeriod(&info.ProvConfig.Spec),
 		},
 	}
 }
 
+func newImagePullSecrets(config *metal3iov1alpha1.ProvisioningSpec) []corev1.LocalObjectReference {
+	var secrets []corev1.LocalObjectReference
+	for _, name := range config.ImagePullSecrets {
+		secrets = append(secrets, corev1.LocalObjectReference{Name: name})
+	}
+	return secrets
+}
+
+// newMetal3PodSecurityContext returns the security context of the metal3 pod.
+// The RuntimeDefault seccomp profile only confines the unprivileged
+// containers: the container runtime runs privileged containers unconfined, so
+// they keep their own security context settings.
+func newMetal3PodSecurityContext(config *metal3iov1alpha1.ProvisioningSpec) *corev1.PodSecurityContext {
+	securityContext := &corev1.PodSecurityContext{
+		RunAsNonRoot: pointer.BoolPtr(false),
+	}
+	if !config.DisableSeccompProfile {
+		securityContext.SeccompProfile = &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		}
+	}
+	return securityContext
+}
+
 func mountsWithTrustedCA(mounts []corev1.VolumeMount) []corev1.VolumeMount {
 	mounts = append(mounts, corev1.VolumeMount{
 		MountPath: "/etc/pki/ca-trust/extracted/pem",
-		Name:      "trusted-ca",
+		Name:      trustedCAVolumeName,
 		ReadOnly:  true,
 	})
 
 	return mounts
 }
 
-func injectProxyAndCA(containers []corev1.Container, proxy *configv1.Proxy) []corev1.Container {
+func injectProxyAndCA(containers []corev1.Container, proxy *configv1.Proxy, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
 	var injectedContainers []corev1.Container
 
 	for _, container := range containers {
-		container.Env = envWithProxy(proxy, container.Env, nil)
+		if !proxyExemptContainers[container.Name] {
+			container.Env = envWithProxy(proxy, config, container.Env, nil)
+		}
 		container.VolumeMounts = mountsWithTrustedCA(container.VolumeMounts)
 		injectedContainers = append(injectedContainers, container)
 	}
@@ -819,9 +1692,18 @@ func injectProxyAndCA(containers []corev1.Container, proxy *configv1.Proxy) []co
 	return injectedContainers
 }
 
-func envWithProxy(proxy *configv1.Proxy, envVars []corev1.EnvVar, noproxy []string) []corev1.EnvVar {
+// envWithProxy adds the cluster proxy settings to envVars. The noproxy entries
+// and the AdditionalNoProxy entries of the config are merged into NO_PROXY,
+// which is set even without a cluster proxy when AdditionalNoProxy is.
+func envWithProxy(proxy *configv1.Proxy, config *metal3iov1alpha1.ProvisioningSpec, envVars []corev1.EnvVar, noproxy []string) []corev1.EnvVar {
 	if proxy == nil {
-		return envVars
+		if len(config.AdditionalNoProxy) == 0 {
+			return envVars
+		}
+		return append(envVars, corev1.EnvVar{
+			Name:  "NO_PROXY",
+			Value: mergeNoProxy(noproxy, config.AdditionalNoProxy),
+		})
 	}
 
 	if proxy.Status.HTTPProxy != "" {
@@ -836,17 +1718,111 @@ func envWithProxy(proxy *configv1.Proxy, envVars []corev1.EnvVar, noproxy []stri
 			Value: proxy.Status.HTTPSProxy,
 		})
 	}
-	if proxy.Status.NoProxy != "" || noproxy != nil {
+	if proxy.Status.NoProxy != "" || noproxy != nil || len(config.AdditionalNoProxy) > 0 {
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  "NO_PROXY",
-			Value: proxy.Status.NoProxy + "," + strings.Join(noproxy, ","),
+			Value: mergeNoProxy(strings.Split(proxy.Status.NoProxy, ","), noproxy, config.AdditionalNoProxy),
 		})
 	}
 
 	return envVars
 }
 
-func newMetal3Deployment(info *ProvisioningInfo) *appsv1.Deployment {
+// mergeNoProxy joins the given no-proxy entries, dropping empty and duplicate
+// ones while keeping the first occurrence order.
+func mergeNoProxy(lists ...[]string) string {
+	seen := map[string]bool{}
+	var entries []string
+	for _, list := range lists {
+		for _, entry := range list {
+			if entry == "" || seen[entry] {
+				continue
+			}
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// defaultMetal3TerminationGracePeriod leaves Ironic time to complete its
+// in-flight node operations on shutdown.
+const defaultMetal3TerminationGracePeriod int64 = 120
+
+func getMetal3TerminationGracePeriod(config *metal3iov1alpha1.ProvisioningSpec) *int64 {
+	if config.TerminationGracePeriodSeconds != nil {
+		return pointer.Int64Ptr(*config.TerminationGracePeriodSeconds)
+	}
+	return pointer.Int64Ptr(defaultMetal3TerminationGracePeriod)
+}
+
+func getMetal3DNSPolicy(config *metal3iov1alpha1.ProvisioningSpec) corev1.DNSPolicy {
+	if config.DNSPolicy != "" {
+		return config.DNSPolicy
+	}
+	return corev1.DNSClusterFirstWithHostNet
+}
+
+func getMetal3PriorityClassName(config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.PriorityClassName != nil {
+		return *config.PriorityClassName
+	}
+	return "system-node-critical"
+}
+
+func getMetal3ServiceAccountName(config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.ServiceAccountName != "" {
+		return config.ServiceAccountName
+	}
+	return defaultMetal3ServiceAccountName
+}
+
+// CheckMetal3ServiceAccount makes sure the service account of the metal3 pod
+// exists, as the replica set would otherwise silently fail to create the pod.
+func CheckMetal3ServiceAccount(info *ProvisioningInfo) error {
+	name := getMetal3ServiceAccountName(&info.ProvConfig.Spec)
+	_, err := info.Client.CoreV1().ServiceAccounts(info.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("service account %s not found in namespace %s", name, info.Namespace)
+	}
+	return err
+}
+
+func newMetal3DeploymentStrategy(info *ProvisioningInfo) appsv1.DeploymentStrategy {
+	// A single node never has the host ports free for a surge pod, which
+	// would stay pending forever and keep the old pod running
+	if info.ProvConfig.Spec.DeploymentStrategy != metal3iov1alpha1.DeploymentStrategyRollingUpdate || info.SingleNodeTopology {
+		return appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}
+	}
+
+	// Surge to avoid any outage, relying on another master to have the
+	// host ports free for the new pod.
+	maxSurge := intstr.FromInt(1)
+	maxUnavailable := intstr.FromInt(0)
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// Metal3DeploymentSurges reports whether updates of the metal3 deployment
+// start a new pod on another node before stopping the old one, so that for
+// a while both serve DHCP and hold the provisioning IP.
+func Metal3DeploymentSurges(info *ProvisioningInfo) bool {
+	strategy := newMetal3DeploymentStrategy(info)
+	return strategy.RollingUpdate != nil && strategy.RollingUpdate.MaxSurge.IntValue() > 0
+}
+
+func newMetal3Deployment(info *ProvisioningInfo) (*appsv1.Deployment, error) {
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return nil, err
+	}
 	selector := &metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			"k8s-app":    metal3AppName,
@@ -857,11 +1833,20 @@ func newMetal3Deployment(info *ProvisioningInfo) *appsv1.Deployment {
 		"k8s-app":    metal3AppName,
 		cboLabelName: stateService,
 	}
+	replicas := int32(1)
 	template := newMetal3PodTemplateSpec(info, &podSpecLabels)
+	if err := withSecretChecksums(info, template); err != nil {
+		return nil, err
+	}
+	if info.ProvConfig.Spec.Paused {
+		// Only the replicas change, so that resuming does not roll out
+		// a different template
+		replicas = 0
+	}
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      baremetalDeploymentName,
-			Namespace: info.Namespace,
+			Namespace: namespace,
 			Annotations: map[string]string{
 				cboOwnedAnnotation: "",
 			},
@@ -871,57 +1856,152 @@ func newMetal3Deployment(info *ProvisioningInfo) *appsv1.Deployment {
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: pointer.Int32Ptr(1),
+			Replicas: pointer.Int32Ptr(replicas),
 			Selector: selector,
 			Template: *template,
-			Strategy: appsv1.DeploymentStrategy{
-				Type: appsv1.RecreateDeploymentStrategyType,
-			},
+			Strategy: newMetal3DeploymentStrategy(info),
+
+			MinReadySeconds: info.ProvConfig.Spec.MinReadySeconds,
 		},
+	}, nil
+}
+
+// RenderMetal3Deployment returns the YAML of the metal3 deployment built for
+// info, for inspecting it without a live cluster. The keys are sorted, so the
+// output is stable for a given info. The secrets referenced by the pod are
+// looked up through info.Client, which may be a fake clientset.
+func RenderMetal3Deployment(info *ProvisioningInfo) ([]byte, error) {
+	deployment, err := newMetal3Deployment(info)
+	if err != nil {
+		return nil, err
 	}
+	deployment.TypeMeta = metav1.TypeMeta{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       "Deployment",
+	}
+	return yaml.Marshal(deployment)
 }
 
-func getMetal3DeploymentSelector(client appsclientv1.DeploymentsGetter, targetNamespace string) (*metav1.LabelSelector, error) {
-	existing, err := client.Deployments(targetNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+func getMetal3DeploymentSelector(info *ProvisioningInfo) (*metav1.LabelSelector, error) {
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := info.Client.AppsV1().Deployments(namespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
 	if existing != nil && err == nil {
 		return existing.Spec.Selector, nil
 	}
 	return nil, err
 }
 
-func EnsureMetal3Deployment(info *ProvisioningInfo) (updated bool, err error) {
+// migrateMetal3DeploymentSelector deletes the metal3 deployment when its
+// selector, which is immutable, differs from the desired one. The operator
+// watches deployments, so the next reconcile recreates it with the new
+// selector, after which there is nothing left to migrate. A failed deletion
+// is returned, and retried with the backoff of the reconcile requeues.
+func migrateMetal3DeploymentSelector(info *ProvisioningInfo, desired *metav1.LabelSelector) (migrated bool, err error) {
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return false, err
+	}
+	existing, err := info.Client.AppsV1().Deployments(namespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec.Selector, desired) {
+		return false, nil
+	}
+	if existing.DeletionTimestamp != nil {
+		// Deleted by an earlier reconcile, wait for it to go away
+		return true, nil
+	}
+
+	// Only delete the deployment whose selector was checked
+	err = info.Client.AppsV1().Deployments(namespace).Delete(context.Background(), baremetalDeploymentName, metav1.DeleteOptions{
+		Preconditions: metav1.NewUIDPreconditions(string(existing.UID)),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("unable to delete Metal3 deployment for selector migration: %w", err)
+	}
+	info.EventRecorder.Eventf("Metal3SelectorMigration", "Recreating deployment %s/%s to change its selector from %s to %s",
+		namespace, baremetalDeploymentName, metav1.FormatLabelSelector(existing.Spec.Selector), metav1.FormatLabelSelector(desired))
+	return true, nil
+}
+
+// newOwnedMetal3Deployment builds the metal3 deployment owned by the
+// Provisioning CR together with the generation expected on the cluster.
+func newOwnedMetal3Deployment(info *ProvisioningInfo) (*appsv1.Deployment, int64, error) {
 	// Create metal3 deployment object based on current baremetal configuration
 	// It will be created with the cboOwnedAnnotation
-
-	metal3Deployment := newMetal3Deployment(info)
+	metal3Deployment, err := newMetal3Deployment(info)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to create Metal3 deployment: %w", err)
+	}
 
 	expectedGeneration := resourcemerge.ExpectedDeploymentGeneration(metal3Deployment, info.ProvConfig.Status.Generations)
 
 	err = controllerutil.SetControllerReference(info.ProvConfig, metal3Deployment, info.Scheme)
 	if err != nil {
-		err = fmt.Errorf("unable to set controllerReference on deployment: %w", err)
+		return nil, 0, fmt.Errorf("unable to set controllerReference on deployment: %w", err)
+	}
+	return metal3Deployment, expectedGeneration, nil
+}
+
+// DryRunMetal3Deployment returns the metal3 deployment EnsureMetal3Deployment
+// would write, and whether it differs from the one on the cluster, using the
+// same merge as resourceapply.ApplyDeployment. Nothing is written.
+func DryRunMetal3Deployment(info *ProvisioningInfo) (*appsv1.Deployment, bool, error) {
+	metal3Deployment, expectedGeneration, err := newOwnedMetal3Deployment(info)
+	if err != nil {
+		return nil, false, err
+	}
+
+	required := metal3Deployment.DeepCopy()
+	err = resourceapply.SetSpecHashAnnotation(&required.ObjectMeta, required.Spec)
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing, err := info.Client.AppsV1().Deployments(required.Namespace).Get(context.Background(), required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return required, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to get Metal3 deployment: %w", err)
+	}
+
+	modified := false
+	deployment := existing.DeepCopy()
+	resourcemerge.EnsureObjectMeta(&modified, &deployment.ObjectMeta, required.ObjectMeta)
+	if !modified && deployment.Generation == expectedGeneration {
+		return deployment, false, nil
+	}
+	deployment.Spec = required.Spec
+	return deployment, true, nil
+}
+
+func EnsureMetal3Deployment(info *ProvisioningInfo) (updated bool, err error) {
+	metal3Deployment, expectedGeneration, err := newOwnedMetal3Deployment(info)
+	if err != nil {
 		return
 	}
 
+	// An older deployment with a different Pod Selector cannot be updated,
+	// it is deleted now and re-created in the next reconcile.
+	migrated, err := migrateMetal3DeploymentSelector(info, metal3Deployment.Spec.Selector)
+	if err != nil || migrated {
+		return migrated, err
+	}
+
 	deploymentRolloutStartTime = time.Now()
 	deployment, updated, err := resourceapply.ApplyDeployment(context.Background(),
 		info.Client.AppsV1(), info.EventRecorder, metal3Deployment, expectedGeneration)
 	if err != nil {
 		err = fmt.Errorf("unable to apply Metal3 deployment: %w", err)
-		// Check if ApplyDeployment failed because the existing Pod had an outdated
-		// Pod Selector.
-		selector, get_err := getMetal3DeploymentSelector(info.Client.AppsV1(), info.Namespace)
-		if get_err != nil || equality.Semantic.DeepEqual(selector, metal3Deployment.Spec.Selector) {
-			return
-		}
-		// This is an older deployment with the incorrect Pod Selector.
-		// Delete deployment now and re-create in the next reconcile.
-		// The operator is watching deployments so the reconcile should be triggered when metal3 deployment
-		// is deleted.
-		if delete_err := DeleteMetal3Deployment(info); delete_err != nil {
-			err = fmt.Errorf("unable to delete Metal3 deployment with incorrect Pod Selector: %w", delete_err)
-			return
-		}
+		return
 	}
 	if updated {
 		resourcemerge.SetDeploymentGeneration(&info.ProvConfig.Status.Generations, deployment)
@@ -929,43 +2009,155 @@ func EnsureMetal3Deployment(info *ProvisioningInfo) (updated bool, err error) {
 	return updated, nil
 }
 
-func getDeploymentCondition(deployment *appsv1.Deployment) appsv1.DeploymentConditionType {
-	var progressing, available, replicaFailure bool
+// DeploymentCrashLoopBackOff is the state of a deployment whose pod has a
+// container in CrashLoopBackOff
+const DeploymentCrashLoopBackOff appsv1.DeploymentConditionType = "CrashLoopBackOff"
+
+// DeploymentTerminationStuck is the state of a deployment whose rollout is
+// blocked by an old pod that does not finish terminating
+const DeploymentTerminationStuck appsv1.DeploymentConditionType = "TerminationStuck"
+
+// DeploymentStatus details the rollout of a deployment
+type DeploymentStatus struct {
+	// State sums up the rollout as Available, Progressing or
+	// ReplicaFailure, the latter also covering rollouts that timed out.
+	State              appsv1.DeploymentConditionType
+	Available          bool
+	Progressing        bool
+	ReadyReplicas      int32
+	Replicas           int32
+	Message            string
+	LastTransitionTime metav1.Time
+}
+
+func newDeploymentStatus(deployment *appsv1.Deployment) DeploymentStatus {
+	status := DeploymentStatus{
+		ReadyReplicas: deployment.Status.ReadyReplicas,
+		Replicas:      1,
+	}
+	if deployment.Spec.Replicas != nil {
+		status.Replicas = *deployment.Spec.Replicas
+	}
+	var replicaFailure bool
+	var failureMessage string
 	for _, cond := range deployment.Status.Conditions {
-		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionTrue {
-			progressing = true
-		}
-		if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
-			available = true
+		if cond.Status != corev1.ConditionTrue {
+			continue
 		}
-		if cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == corev1.ConditionTrue {
+		switch cond.Type {
+		case appsv1.DeploymentProgressing:
+			status.Progressing = true
+		case appsv1.DeploymentAvailable:
+			status.Available = true
+			status.LastTransitionTime = cond.LastTransitionTime
+		case appsv1.DeploymentReplicaFailure:
 			replicaFailure = true
+			failureMessage = cond.Message
 		}
 	}
+
 	switch {
-	case replicaFailure && !progressing:
-		return appsv1.DeploymentReplicaFailure
-	case available && !replicaFailure:
-		return appsv1.DeploymentAvailable
+	case replicaFailure && !status.Progressing:
+		status.State = appsv1.DeploymentReplicaFailure
+	case status.Available && !replicaFailure:
+		status.State = appsv1.DeploymentAvailable
 	default:
-		return appsv1.DeploymentProgressing
+		status.State = appsv1.DeploymentProgressing
+	}
+
+	status.Message = fmt.Sprintf("%d/%d replicas ready", status.ReadyReplicas, status.Replicas)
+	if replicaFailure && failureMessage != "" {
+		status.Message += ": " + failureMessage
 	}
+	return status
 }
 
-// Provide the current state of metal3 deployment
-func GetDeploymentState(client appsclientv1.DeploymentsGetter, targetNamespace string, config *metal3iov1alpha1.Provisioning) (appsv1.DeploymentConditionType, error) {
-	existing, err := client.Deployments(targetNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+func getDeploymentCondition(deployment *appsv1.Deployment) appsv1.DeploymentConditionType {
+	return newDeploymentStatus(deployment).State
+}
+
+// GetDeploymentStatus provides the detailed rollout status of the metal3
+// deployment
+func GetDeploymentStatus(info *ProvisioningInfo) (DeploymentStatus, error) {
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return DeploymentStatus{State: appsv1.DeploymentReplicaFailure}, err
+	}
+	existing, err := info.Client.AppsV1().Deployments(namespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
 	if err != nil || existing == nil {
 		// There were errors accessing the deployment.
-		return appsv1.DeploymentReplicaFailure, err
+		return DeploymentStatus{State: appsv1.DeploymentReplicaFailure}, err
+	}
+	status := newDeploymentStatus(existing)
+	// The pods only become available MinReadySeconds after being ready
+	rolloutTimeout := deploymentRolloutTimeout + time.Duration(existing.Spec.MinReadySeconds)*time.Second
+	if status.State == appsv1.DeploymentProgressing && rolloutTimeout <= time.Since(deploymentRolloutStartTime) {
+		status.State = appsv1.DeploymentReplicaFailure
+		status.Message += ", rollout timed out"
 	}
-	deploymentState := getDeploymentCondition(existing)
-	if deploymentState == appsv1.DeploymentProgressing && deploymentRolloutTimeout <= time.Since(deploymentRolloutStartTime) {
-		return appsv1.DeploymentReplicaFailure, nil
+	// The deployment conditions do not tell which container is failing.
+	// The pod is only looked up to enrich the status, errors are ignored.
+	if pod, err := getPod(info.Client.CoreV1(), namespace); err == nil {
+		if failure := crashLoopingContainer(pod); failure != "" {
+			status.State = DeploymentCrashLoopBackOff
+			status.Message += ", " + failure
+			return status, nil
+		} else if failure := initContainerFailure(pod); failure != "" && !status.Available {
+			status.Message += ", " + failure
+		}
 	}
-	return deploymentState, nil
+	if !status.Available {
+		if pods, err := listMetal3Pods(info.Client.CoreV1(), namespace); err == nil {
+			if stuck := stuckTerminatingPod(pods); stuck != "" {
+				status.State = DeploymentTerminationStuck
+				status.Message += ", " + stuck
+			}
+		}
+	}
+	return status, nil
+}
+
+// Provide the current state of metal3 deployment
+func GetDeploymentState(info *ProvisioningInfo) (appsv1.DeploymentConditionType, error) {
+	status, err := GetDeploymentStatus(info)
+	return status.State, err
+}
+
+// IsMetal3DeploymentConverged reports whether the latest spec of the metal3
+// deployment is fully rolled out: observed by the deployment controller, with
+// all the desired replicas updated and available and no old replica left.
+// When it is not, the reason tells what the rollout is waiting for.
+func IsMetal3DeploymentConverged(deploymentClient appsclientv1.DeploymentsGetter, targetNamespace string) (bool, string, error) {
+	deployment, err := deploymentClient.Deployments(targetNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, "metal3 deployment not found", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	switch {
+	case status.ObservedGeneration < deployment.Generation:
+		return false, fmt.Sprintf("generation %d not observed yet, last observed %d", deployment.Generation, status.ObservedGeneration), nil
+	case status.UpdatedReplicas < desired:
+		return false, fmt.Sprintf("%d/%d replicas updated", status.UpdatedReplicas, desired), nil
+	case status.Replicas > status.UpdatedReplicas:
+		return false, fmt.Sprintf("%d old replicas pending termination", status.Replicas-status.UpdatedReplicas), nil
+	case status.AvailableReplicas < desired:
+		return false, fmt.Sprintf("%d/%d replicas available", status.AvailableReplicas, desired), nil
+	}
+	return true, "", nil
 }
 
 func DeleteMetal3Deployment(info *ProvisioningInfo) error {
-	return client.IgnoreNotFound(info.Client.AppsV1().Deployments(info.Namespace).Delete(context.Background(), baremetalDeploymentName, metav1.DeleteOptions{}))
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return err
+	}
+	return client.IgnoreNotFound(info.Client.AppsV1().Deployments(namespace).Delete(context.Background(), baremetalDeploymentName, metav1.DeleteOptions{}))
 }
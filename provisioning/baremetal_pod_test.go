@@ -16,17 +16,33 @@ limitations under the License.
 package provisioning
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	faketesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
 
 	osconfigv1 "github.com/openshift/api/config/v1"
 	v1 "github.com/openshift/api/config/v1"
 	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 
 	fakekube "k8s.io/client-go/kubernetes/fake"
 )
@@ -74,6 +90,58 @@ func TestBuildEnvVar(t *testing.T) {
 				Value: "",
 			},
 		},
+		{
+			name:       "Disabled ProvisioningIPCIDR",
+			configName: provisioningIP,
+			spec:       disabledProvisioning().build(),
+			expectedEnvVar: corev1.EnvVar{
+				Name:  provisioningIP,
+				Value: "172.30.20.3/24",
+			},
+		},
+		{
+			name:       "Disabled ProvisioningIP defaults to hostIP",
+			configName: provisioningIP,
+			spec:       disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").build(),
+			expectedEnvVar: corev1.EnvVar{
+				Name: provisioningIP,
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "status.hostIP",
+					},
+				},
+			},
+		},
+		{
+			name:       "Disabled ProvisioningIP HostIP source",
+			configName: provisioningIP,
+			spec:       disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").ProvisioningIPSource(metal3iov1alpha1.ProvisioningIPSourceHostIP).build(),
+			expectedEnvVar: corev1.EnvVar{
+				Name: provisioningIP,
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "status.hostIP",
+					},
+				},
+			},
+		},
+		{
+			name:       "Disabled ProvisioningIP Interface source",
+			configName: provisioningIP,
+			spec:       disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").ProvisioningInterface("eth1").ProvisioningIPSource(metal3iov1alpha1.ProvisioningIPSourceInterface).build(),
+			expectedEnvVar: corev1.EnvVar{
+				Name: provisioningIP,
+			},
+		},
+		{
+			name:       "Disabled ProvisioningInterface Interface source",
+			configName: provisioningInterface,
+			spec:       disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").ProvisioningInterface("eth1").ProvisioningIPSource(metal3iov1alpha1.ProvisioningIPSourceInterface).build(),
+			expectedEnvVar: corev1.EnvVar{
+				Name:  provisioningInterface,
+				Value: "eth1",
+			},
+		},
 	}
 	for _, tc := range tCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -85,6 +153,48 @@ func TestBuildEnvVar(t *testing.T) {
 	}
 }
 
+func TestSetIronicExternalIp(t *testing.T) {
+	hostIP := corev1.EnvVar{
+		Name: externalIpEnvVar,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: "status.hostIP",
+			},
+		},
+	}
+	tCases := []struct {
+		name           string
+		spec           *metal3iov1alpha1.ProvisioningSpec
+		expectedEnvVar corev1.EnvVar
+	}{
+		{
+			name:           "Managed",
+			spec:           managedProvisioning().build(),
+			expectedEnvVar: corev1.EnvVar{Name: externalIpEnvVar},
+		},
+		{
+			name:           "Managed VirtualMediaViaExternalNetwork",
+			spec:           managedProvisioning().VirtualMediaViaExternalNetwork(true).build(),
+			expectedEnvVar: hostIP,
+		},
+		{
+			name:           "Disabled",
+			spec:           disabledProvisioning().VirtualMediaViaExternalNetwork(true).build(),
+			expectedEnvVar: corev1.EnvVar{Name: externalIpEnvVar},
+		},
+		{
+			name:           "Disabled Interface source",
+			spec:           disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").ProvisioningInterface("eth1").ProvisioningIPSource(metal3iov1alpha1.ProvisioningIPSourceInterface).build(),
+			expectedEnvVar: corev1.EnvVar{Name: externalIpEnvVar},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedEnvVar, setIronicExternalIp(externalIpEnvVar, tc.spec))
+		})
+	}
+}
+
 func TestNewMetal3InitContainers(t *testing.T) {
 	images := Images{
 		BaremetalOperator:   expectedBaremetalOperator,
@@ -161,6 +271,64 @@ func TestNewMetal3InitContainers(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "valid config with provisioning interface check",
+			config: managedProvisioning().CheckProvisioningInterface().build(),
+			expectedContainers: []corev1.Container{
+				{
+					Name:  "metal3-provisioning-interface-check",
+					Image: images.Ironic,
+				},
+				{
+					Name:  "metal3-static-ip-set",
+					Image: images.StaticIpManager,
+				},
+				{
+					Name:  "machine-os-images",
+					Image: images.MachineOSImages,
+				},
+				{
+					Name:  "metal3-machine-os-downloader",
+					Image: images.MachineOsDownloader,
+				},
+			},
+		},
+		{
+			name:   "disabled with provisioning interface check",
+			config: disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").CheckProvisioningInterface().build(),
+			expectedContainers: []corev1.Container{
+				{
+					Name:  "machine-os-images",
+					Image: images.MachineOSImages,
+				},
+				{
+					Name:  "metal3-machine-os-downloader",
+					Image: images.MachineOsDownloader,
+				},
+			},
+		},
+		{
+			name:   "valid config with ironic image pre-pull",
+			config: managedProvisioning().PrePullIronicImage().build(),
+			expectedContainers: []corev1.Container{
+				{
+					Name:  "metal3-static-ip-set",
+					Image: images.StaticIpManager,
+				},
+				{
+					Name:  "machine-os-images",
+					Image: images.MachineOSImages,
+				},
+				{
+					Name:  "metal3-machine-os-downloader",
+					Image: images.MachineOsDownloader,
+				},
+				{
+					Name:  "metal3-ironic-pre-pull",
+					Image: images.Ironic,
+				},
+			},
+		},
 	}
 	for _, tc := range tCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -168,6 +336,11 @@ func TestNewMetal3InitContainers(t *testing.T) {
 			info := &ProvisioningInfo{Images: &images, ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config}}
 			actualContainers := newMetal3InitContainers(info)
 			assert.Equal(t, len(tc.expectedContainers), len(actualContainers), fmt.Sprintf("%s : Expected number of Init Containers : %d Actual number of Init Containers : %d", tc.name, len(tc.expectedContainers), len(actualContainers)))
+			for i, container := range actualContainers {
+				if i < len(tc.expectedContainers) {
+					assert.Equal(t, tc.expectedContainers[i].Name, container.Name)
+				}
+			}
 		})
 	}
 }
@@ -177,6 +350,7 @@ func TestNewMetal3Containers(t *testing.T) {
 		return corev1.EnvVar{Name: name, Value: value}
 	}
 	sshkey := envWithValue("IRONIC_RAMDISK_SSH_KEY", "sshkey")
+	callbackURL := envWithValue("IRONIC_EXTERNAL_CALLBACK_URL", "https://172.30.20.3:6385")
 	envWithFieldValue := func(name, fieldPath string) corev1.EnvVar {
 		return corev1.EnvVar{
 			Name:  name,
@@ -317,12 +491,12 @@ func TestNewMetal3Containers(t *testing.T) {
 			name:   "ManagedSpec",
 			config: managedProvisioning().build(),
 			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
 				withEnv(containers["metal3-httpd"], sshkey),
-				withEnv(containers["metal3-ironic"], sshkey),
-				containers["metal3-ramdisk-logs"],
+				withEnv(containers["metal3-ironic"], sshkey, callbackURL),
 				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
 				containers["metal3-static-ip-manager"],
-				containers["metal3-dnsmasq"],
 			},
 			sshkey: "sshkey",
 		},
@@ -330,97 +504,401 @@ func TestNewMetal3Containers(t *testing.T) {
 			name:   "ManagedSpec with DNS",
 			config: managedProvisioning().ProvisioningDNS(true).build(),
 			expectedContainers: []corev1.Container{
+				withEnv(
+					containers["metal3-dnsmasq"],
+					envWithValue("DNS_IP", "provisioning"),
+				),
 				withEnv(containers["metal3-httpd"], sshkey),
-				withEnv(containers["metal3-ironic"], sshkey),
-				containers["metal3-ramdisk-logs"],
+				withEnv(containers["metal3-ironic"], sshkey, callbackURL),
 				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
 				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with BMC polling overrides",
+			config: managedProvisioning().BMCPollingOverrides(map[string]string{"redfish": "30s", "idrac": "2m"}).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
 				withEnv(
-					containers["metal3-dnsmasq"],
-					envWithValue("DNS_IP", "provisioning"),
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("IRONIC_BMC_POLLING_OVERRIDES", "idrac:2m,redfish:30s"),
+					callbackURL,
 				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
 			},
 			sshkey: "sshkey",
 		},
 		{
-			name:   "ManagedSpec with virtualmedia",
-			config: managedProvisioning().VirtualMediaViaExternalNetwork(true).build(),
+			name:   "ManagedSpec with debug logging",
+			config: managedProvisioning().LogLevel(metal3iov1alpha1.LogLevelDebug).build(),
 			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
 				withEnv(
-					containers["metal3-httpd"],
+					containers["metal3-ironic"],
 					sshkey,
-					envWithValue("IRONIC_LISTEN_PORT", "6388"),
-					envWithValue("IRONIC_INSPECTOR_LISTEN_PORT", "5051"),
+					envWithValue("IRONIC_LOG_LEVEL", "DEBUG"),
+					envWithValue("OS_DEFAULT__DEBUG", "true"),
+					callbackURL,
+				),
+				withEnv(
+					containers["metal3-ironic-inspector"],
+					envWithValue("IRONIC_LOG_LEVEL", "DEBUG"),
+					envWithValue("OS_DEFAULT__DEBUG", "true"),
 				),
-				withEnv(containers["metal3-ironic"], sshkey, envWithFieldValue("IRONIC_EXTERNAL_IP", "status.hostIP")),
 				containers["metal3-ramdisk-logs"],
-				containers["metal3-ironic-inspector"],
 				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with error logging",
+			config: managedProvisioning().LogLevel(metal3iov1alpha1.LogLevelError).build(),
+			expectedContainers: []corev1.Container{
 				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("IRONIC_LOG_LEVEL", "ERROR"),
+					envWithValue("OS_DEFAULT__DEBUG", "false"),
+					callbackURL,
+				),
+				withEnv(
+					containers["metal3-ironic-inspector"],
+					envWithValue("IRONIC_LOG_LEVEL", "ERROR"),
+					envWithValue("OS_DEFAULT__DEBUG", "false"),
+				),
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
 			},
 			sshkey: "sshkey",
 		},
 		{
-			name:   "UnmanagedSpec",
-			config: unmanagedProvisioning().build(),
+			name:   "ManagedSpec with noop network interface",
+			config: managedProvisioning().NetworkInterface(metal3iov1alpha1.NetworkInterfaceNoop).build(),
 			expectedContainers: []corev1.Container{
-				withEnv(containers["metal3-httpd"], envWithValue("PROVISIONING_INTERFACE", "ensp0")),
-				withEnv(containers["metal3-ironic"], envWithValue("PROVISIONING_INTERFACE", "ensp0")),
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_DEFAULT__DEFAULT_NETWORK_INTERFACE", "noop"),
+					callbackURL,
+				),
+				containers["metal3-ironic-inspector"],
 				containers["metal3-ramdisk-logs"],
-				withEnv(containers["metal3-ironic-inspector"], envWithValue("PROVISIONING_INTERFACE", "ensp0")),
-				withEnv(containers["metal3-static-ip-manager"], envWithValue("PROVISIONING_INTERFACE", "ensp0")),
-				withEnv(containers["metal3-dnsmasq"], envWithValue("PROVISIONING_INTERFACE", "ensp0"), envWithValue("DHCP_RANGE", "")),
+				containers["metal3-static-ip-manager"],
 			},
-			sshkey: "",
+			sshkey: "sshkey",
 		},
 		{
-			name:   "DisabledSpec",
-			config: disabledProvisioning().build(),
+			name:   "ManagedSpec with retirement workflow",
+			config: managedProvisioning().RetirementWorkflow(true, "deploy.erase_devices_metadata", "raid.delete_configuration").build(),
 			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
 				withEnv(
-					containers["metal3-httpd"],
-					envWithValue("PROVISIONING_INTERFACE", ""),
-					envWithValue("IRONIC_LISTEN_PORT", "6388"),
-					envWithValue("IRONIC_INSPECTOR_LISTEN_PORT", "5051"),
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_CONDUCTOR__AUTOMATED_CLEAN", "true"),
+					envWithValue("OS_CONDUCTOR__CLEAN_STEP_PRIORITY_OVERRIDE", "deploy.erase_devices_metadata:2,raid.delete_configuration:1"),
+					callbackURL,
 				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with image download timeout",
+			config: managedProvisioning().ImageDownloadTimeout(90 * time.Minute).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
 				withEnv(
 					containers["metal3-ironic"],
-					envWithValue("PROVISIONING_INTERFACE", ""),
-					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+					sshkey,
+					envWithValue("OS_DEFAULT__WEBSERVER_CONNECTION_TIMEOUT", "5400"),
+					callbackURL,
 				),
+				containers["metal3-ironic-inspector"],
 				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with deploy timeout",
+			config: managedProvisioning().DeployTimeout(2 * time.Hour).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
 				withEnv(
-					containers["metal3-ironic-inspector"],
-					envWithValue("PROVISIONING_INTERFACE", ""),
-					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_CONDUCTOR__DEPLOY_CALLBACK_TIMEOUT", "7200"),
+					callbackURL,
 				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
 			},
-			sshkey: "",
+			sshkey: "sshkey",
 		},
 		{
-			name:   "DisabledSpecWithoutProvisioningIP",
-			config: disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").build(),
+			name:   "ManagedSpec with power state sync interval",
+			config: managedProvisioning().PowerStateSyncInterval(5 * time.Minute).build(),
 			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
 				withEnv(
-					containers["metal3-httpd"],
-					envWithValue("PROVISIONING_INTERFACE", ""),
-					envWithFieldValue("PROVISIONING_IP", "status.hostIP"),
-					envWithValue("IRONIC_LISTEN_PORT", "6388"),
-					envWithValue("IRONIC_INSPECTOR_LISTEN_PORT", "5051"),
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_CONDUCTOR__SYNC_POWER_STATE_INTERVAL", "300"),
+					callbackURL,
 				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with image download timeout, deploy timeout and power state sync interval",
+			config: managedProvisioning().ImageDownloadTimeout(time.Hour).DeployTimeout(2 * time.Hour).PowerStateSyncInterval(5 * time.Minute).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
 				withEnv(
 					containers["metal3-ironic"],
-					envWithValue("PROVISIONING_INTERFACE", ""),
-					envWithFieldValue("PROVISIONING_IP", "status.hostIP"),
-					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+					sshkey,
+					envWithValue("OS_DEFAULT__WEBSERVER_CONNECTION_TIMEOUT", "3600"),
+					envWithValue("OS_CONDUCTOR__DEPLOY_CALLBACK_TIMEOUT", "7200"),
+					envWithValue("OS_CONDUCTOR__SYNC_POWER_STATE_INTERVAL", "300"),
+					callbackURL,
 				),
+				containers["metal3-ironic-inspector"],
 				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with inspection timeout",
+			config: managedProvisioning().InspectionTimeout(90 * time.Minute).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(containers["metal3-ironic"], sshkey, callbackURL),
 				withEnv(
 					containers["metal3-ironic-inspector"],
-					envWithValue("PROVISIONING_INTERFACE", ""),
-					envWithFieldValue("PROVISIONING_IP", "status.hostIP"),
-					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+					envWithValue("OS_DEFAULT__TIMEOUT", "5400"),
 				),
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			// The Ironic container runs both the API and the conductor
+			name:   "ManagedSpec with JSON-RPC settings",
+			config: managedProvisioning().RPC(metal3iov1alpha1.RPCAuthStrategyHTTPBasic, 2*time.Minute).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_JSON_RPC__AUTH_STRATEGY", "http_basic"),
+					envWithValue("OS_JSON_RPC__TIMEOUT", "120"),
+					callbackURL,
+				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with DHCP lease time",
+			config: managedProvisioning().DHCPLeaseTime(30 * time.Minute).build(),
+			expectedContainers: []corev1.Container{
+				withEnv(containers["metal3-dnsmasq"], envWithValue("DHCP_LEASE_TIME", "1800")),
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(containers["metal3-ironic"], sshkey, callbackURL),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with TFTP block size",
+			config: managedProvisioning().TFTPBlockSize(1468).build(),
+			expectedContainers: []corev1.Container{
+				withEnv(containers["metal3-dnsmasq"], envWithValue("TFTP_BLOCK_SIZE", "1468")),
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(containers["metal3-ironic"], sshkey, callbackURL),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with provisioning interface MTU",
+			config: managedProvisioning().ProvisioningInterfaceMTU(9000).build(),
+			expectedContainers: []corev1.Container{
+				withEnv(containers["metal3-dnsmasq"], envWithValue("PROVISIONING_INTERFACE_MTU", "9000")),
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(containers["metal3-ironic"], sshkey, callbackURL),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				withEnv(containers["metal3-static-ip-manager"], envWithValue("PROVISIONING_INTERFACE_MTU", "9000")),
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with software RAID root",
+			config: managedProvisioning().SoftwareRAIDRoot(true).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_DEFAULT__DEFAULT_RAID_INTERFACE", "agent"),
+					callbackURL,
+				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with conductor concurrency",
+			config: managedProvisioning().ConductorConcurrency(50).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_CONDUCTOR__MAX_CONCURRENT_DEPLOY", "50"),
+					callbackURL,
+				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with conductor group",
+			config: managedProvisioning().ConductorGroup("rack-1").build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(containers["metal3-httpd"], sshkey),
+				withEnv(
+					containers["metal3-ironic"],
+					sshkey,
+					envWithValue("OS_CONDUCTOR__CONDUCTOR_GROUP", "rack-1"),
+					callbackURL,
+				),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpec with virtualmedia",
+			config: managedProvisioning().VirtualMediaViaExternalNetwork(true).build(),
+			expectedContainers: []corev1.Container{
+				containers["metal3-dnsmasq"],
+				withEnv(
+					containers["metal3-httpd"],
+					sshkey,
+					envWithValue("IRONIC_LISTEN_PORT", "6388"),
+					envWithValue("IRONIC_INSPECTOR_LISTEN_PORT", "5051"),
+				),
+				withEnv(containers["metal3-ironic"], sshkey, envWithFieldValue("IRONIC_EXTERNAL_IP", "status.hostIP")),
+				containers["metal3-ironic-inspector"],
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "UnmanagedSpec",
+			config: unmanagedProvisioning().build(),
+			expectedContainers: []corev1.Container{
+				withEnv(containers["metal3-dnsmasq"], envWithValue("PROVISIONING_INTERFACE", "ensp0"), envWithValue("DHCP_RANGE", "")),
+				withEnv(containers["metal3-httpd"], envWithValue("PROVISIONING_INTERFACE", "ensp0")),
+				withEnv(containers["metal3-ironic"], envWithValue("PROVISIONING_INTERFACE", "ensp0"), callbackURL),
+				withEnv(containers["metal3-ironic-inspector"], envWithValue("PROVISIONING_INTERFACE", "ensp0")),
+				containers["metal3-ramdisk-logs"],
+				withEnv(containers["metal3-static-ip-manager"], envWithValue("PROVISIONING_INTERFACE", "ensp0")),
+			},
+			sshkey: "",
+		},
+		{
+			name:   "DisabledSpec",
+			config: disabledProvisioning().build(),
+			expectedContainers: []corev1.Container{
+				withEnv(
+					containers["metal3-httpd"],
+					envWithValue("PROVISIONING_INTERFACE", ""),
+					envWithValue("IRONIC_LISTEN_PORT", "6388"),
+					envWithValue("IRONIC_INSPECTOR_LISTEN_PORT", "5051"),
+				),
+				withEnv(
+					containers["metal3-ironic"],
+					envWithValue("PROVISIONING_INTERFACE", ""),
+					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+				),
+				withEnv(
+					containers["metal3-ironic-inspector"],
+					envWithValue("PROVISIONING_INTERFACE", ""),
+					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+				),
+				containers["metal3-ramdisk-logs"],
+			},
+			sshkey: "",
+		},
+		{
+			name:   "DisabledSpecWithoutProvisioningIP",
+			config: disabledProvisioning().ProvisioningIP("").ProvisioningNetworkCIDR("").build(),
+			expectedContainers: []corev1.Container{
+				withEnv(
+					containers["metal3-httpd"],
+					envWithValue("PROVISIONING_INTERFACE", ""),
+					envWithFieldValue("PROVISIONING_IP", "status.hostIP"),
+					envWithValue("IRONIC_LISTEN_PORT", "6388"),
+					envWithValue("IRONIC_INSPECTOR_LISTEN_PORT", "5051"),
+				),
+				withEnv(
+					containers["metal3-ironic"],
+					envWithValue("PROVISIONING_INTERFACE", ""),
+					envWithFieldValue("PROVISIONING_IP", "status.hostIP"),
+					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+				),
+				withEnv(
+					containers["metal3-ironic-inspector"],
+					envWithValue("PROVISIONING_INTERFACE", ""),
+					envWithFieldValue("PROVISIONING_IP", "status.hostIP"),
+					envWithValue("IRONIC_KERNEL_PARAMS", "rd.net.timeout.carrier=30 ip=dhcp6"),
+				),
+				containers["metal3-ramdisk-logs"],
 			},
 			sshkey: "",
 		},
@@ -542,9 +1020,15 @@ func TestProxyAndCAInjection(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Logf("Testing tc : %s", tc.name)
 			for _, container := range tc.containers {
-				assert.Contains(t, container.Env, corev1.EnvVar{Name: "HTTP_PROXY", Value: "https://172.2.0.1:3128"})
-				assert.Contains(t, container.Env, corev1.EnvVar{Name: "HTTPS_PROXY", Value: "https://172.2.0.1:3128"})
-				assert.Contains(t, container.Env, corev1.EnvVar{Name: "NO_PROXY", Value: ".example.com,"})
+				if container.Name == "metal3-ramdisk-logs" {
+					for _, env := range container.Env {
+						assert.NotContains(t, []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"}, env.Name)
+					}
+				} else {
+					assert.Contains(t, container.Env, corev1.EnvVar{Name: "HTTP_PROXY", Value: "https://172.2.0.1:3128"})
+					assert.Contains(t, container.Env, corev1.EnvVar{Name: "HTTPS_PROXY", Value: "https://172.2.0.1:3128"})
+					assert.Contains(t, container.Env, corev1.EnvVar{Name: "NO_PROXY", Value: ".example.com"})
+				}
 
 				assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{
 					MountPath: "/etc/pki/ca-trust/extracted/pem",
@@ -555,3 +1039,2237 @@ func TestProxyAndCAInjection(t *testing.T) {
 		})
 	}
 }
+
+func TestContainersDropCapabilities(t *testing.T) {
+	info := &ProvisioningInfo{
+		Namespace: "openshift-machine-api",
+		Images: &Images{
+			BaremetalOperator:   expectedBaremetalOperator,
+			Ironic:              expectedIronic,
+			MachineOsDownloader: expectedMachineOsDownloader,
+			StaticIpManager:     expectedIronicStaticIpManager,
+		},
+		ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+		NetworkStack: NetworkStackV4,
+		Client:       fakekube.NewSimpleClientset(),
+		OSClient:     fakeconfigclientset.NewSimpleClientset(),
+	}
+	bmoContainer, err := createContainerBaremetalOperator(info)
+	assert.NoError(t, err)
+	containers := append(newMetal3Containers(info), bmoContainer)
+
+	expectedDropAll := map[string]bool{
+		"metal3-baremetal-operator": true,
+		"metal3-ramdisk-logs":       true,
+		"metal3-httpd":              false,
+		"metal3-ironic":             false,
+		"metal3-dnsmasq":            false,
+		"metal3-static-ip-manager":  false,
+	}
+	for _, container := range containers {
+		dropAll, ok := expectedDropAll[container.Name]
+		if !ok {
+			continue
+		}
+		delete(expectedDropAll, container.Name)
+		if !dropAll {
+			assert.True(t, *container.SecurityContext.Privileged, "container %s", container.Name)
+			assert.Nil(t, container.SecurityContext.Capabilities, "container %s", container.Name)
+			continue
+		}
+		assert.Equal(t, []corev1.Capability{"ALL"}, container.SecurityContext.Capabilities.Drop, "container %s", container.Name)
+		assert.Nil(t, container.SecurityContext.Privileged, "container %s", container.Name)
+	}
+	assert.Empty(t, expectedDropAll)
+}
+
+func TestInternalTLS(t *testing.T) {
+	for _, internalTLS := range []bool{false, true} {
+		t.Run(fmt.Sprintf("internalTLS=%v", internalTLS), func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Namespace: "openshift-machine-api",
+				Images: &Images{
+					BaremetalOperator: expectedBaremetalOperator,
+					Ironic:            expectedIronic,
+				},
+				ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().InternalTLS(internalTLS).build()},
+				NetworkStack: NetworkStackV4,
+				Client:       fakekube.NewSimpleClientset(),
+				OSClient:     fakeconfigclientset.NewSimpleClientset(),
+			}
+			config := &info.ProvConfig.Spec
+			insecure := fmt.Sprint(!internalTLS)
+			ironicCert := corev1.EnvVar{Name: "IRONIC_CACERT_FILE", Value: "/certs/ironic/tls.crt"}
+			inspectorCert := corev1.EnvVar{Name: "IRONIC_INSPECTOR_CACERT_FILE", Value: "/certs/ironic-inspector/tls.crt"}
+
+			ironic := createContainerMetal3Ironic(info.Images, info, config, "")
+			assert.Contains(t, ironic.Env, corev1.EnvVar{Name: "IRONIC_INSECURE", Value: insecure})
+			assert.Contains(t, ironic.Env, corev1.EnvVar{Name: "IRONIC_INSPECTOR_INSECURE", Value: insecure})
+
+			inspector := createContainerMetal3IronicInspector(info.Images, info, config)
+			assert.Contains(t, inspector.Env, corev1.EnvVar{Name: "IRONIC_INSECURE", Value: insecure})
+			assert.NotContains(t, inspector.Env, inspectorCert)
+
+			if internalTLS {
+				assert.Contains(t, ironic.Env, ironicCert)
+				assert.Contains(t, ironic.Env, inspectorCert)
+				assert.Contains(t, inspector.Env, ironicCert)
+			} else {
+				assert.NotContains(t, ironic.Env, ironicCert)
+				assert.NotContains(t, ironic.Env, inspectorCert)
+				assert.NotContains(t, inspector.Env, ironicCert)
+			}
+
+			bmo, err := createContainerBaremetalOperator(info)
+			assert.NoError(t, err)
+			assert.Contains(t, bmo.Env, corev1.EnvVar{Name: "IRONIC_INSECURE", Value: insecure})
+			assert.Contains(t, bmo.Env, ironicCert)
+		})
+	}
+}
+
+func TestNewMetal3ContainersResourceRequests(t *testing.T) {
+	info := &ProvisioningInfo{
+		Images: &Images{
+			BaremetalOperator:   expectedBaremetalOperator,
+			Ironic:              expectedIronic,
+			MachineOsDownloader: expectedMachineOsDownloader,
+			StaticIpManager:     expectedIronicStaticIpManager,
+		},
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			Spec: *managedProvisioning().ResourceRequests("metal3-ironic", corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			}).build(),
+		},
+	}
+	for _, container := range newMetal3Containers(info) {
+		switch container.Name {
+		case "metal3-ironic":
+			// Only the memory request is overridden
+			assert.Equal(t, resource.MustParse("2Gi"), container.Resources.Requests[corev1.ResourceMemory])
+			assert.Equal(t, resource.MustParse("50m"), container.Resources.Requests[corev1.ResourceCPU])
+		case "metal3-httpd":
+			assert.Equal(t, createContainerMetal3Httpd(info.Images, &info.ProvConfig.Spec, "").Resources, container.Resources)
+		}
+	}
+}
+
+func TestDownloadProxy(t *testing.T) {
+	info := &ProvisioningInfo{
+		Images: &Images{
+			BaremetalOperator:   expectedBaremetalOperator,
+			Ironic:              expectedIronic,
+			MachineOsDownloader: expectedMachineOsDownloader,
+			StaticIpManager:     expectedIronicStaticIpManager,
+		},
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			Spec: *managedProvisioning().DownloadProxy("http://cache.example.com:3128", "").build(),
+		},
+		Proxy: &v1.Proxy{
+			Status: v1.ProxyStatus{
+				HTTPProxy:  "https://172.2.0.1:3128",
+				HTTPSProxy: "https://172.2.0.1:3128",
+				NoProxy:    ".example.com",
+			},
+		},
+		NetworkStack: NetworkStackV4,
+	}
+	clusterProxy := []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: "https://172.2.0.1:3128"},
+		{Name: "HTTPS_PROXY", Value: "https://172.2.0.1:3128"},
+		{Name: "NO_PROXY", Value: ".example.com"},
+	}
+	proxyEnv := func(container corev1.Container) []corev1.EnvVar {
+		var env []corev1.EnvVar
+		for _, envVar := range container.Env {
+			if strings.HasSuffix(envVar.Name, "_PROXY") {
+				env = append(env, envVar)
+			}
+		}
+		return env
+	}
+
+	downloaders := 0
+	for _, container := range newMetal3InitContainers(info) {
+		if container.Name == machineOsDownloaderContainerName {
+			downloaders++
+			assert.ElementsMatch(t, []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: "http://cache.example.com:3128"},
+				{Name: "NO_PROXY", Value: ".example.com"},
+			}, proxyEnv(container))
+		} else {
+			assert.ElementsMatch(t, clusterProxy, proxyEnv(container), container.Name)
+		}
+	}
+	assert.Equal(t, 1, downloaders)
+
+	for _, container := range newMetal3Containers(info) {
+		if !proxyExemptContainers[container.Name] {
+			assert.ElementsMatch(t, clusterProxy, proxyEnv(container), container.Name)
+		}
+	}
+}
+
+func TestEnvWithProxyAdditionalNoProxy(t *testing.T) {
+	proxy := &v1.Proxy{
+		Status: v1.ProxyStatus{
+			HTTPProxy: "https://172.2.0.1:3128",
+			NoProxy:   ".example.com,172.22.0.3",
+		},
+	}
+	tCases := []struct {
+		name            string
+		proxy           *v1.Proxy
+		config          *metal3iov1alpha1.ProvisioningSpec
+		noproxy         []string
+		expectedNoProxy *corev1.EnvVar
+	}{
+		{
+			name:            "proxy only",
+			proxy:           proxy,
+			config:          managedProvisioning().build(),
+			expectedNoProxy: &corev1.EnvVar{Name: "NO_PROXY", Value: ".example.com,172.22.0.3"},
+		},
+		{
+			name:            "merged and deduplicated",
+			proxy:           proxy,
+			config:          managedProvisioning().AdditionalNoProxy("172.22.0.3", "registry.example.com").build(),
+			noproxy:         []string{"172.22.0.4", "172.22.0.3"},
+			expectedNoProxy: &corev1.EnvVar{Name: "NO_PROXY", Value: ".example.com,172.22.0.3,172.22.0.4,registry.example.com"},
+		},
+		{
+			name:            "no proxy and no additional entries",
+			config:          managedProvisioning().build(),
+			noproxy:         []string{"172.22.0.4"},
+			expectedNoProxy: nil,
+		},
+		{
+			name:            "additional entries without proxy",
+			config:          managedProvisioning().AdditionalNoProxy("registry.example.com", "registry.example.com").build(),
+			expectedNoProxy: &corev1.EnvVar{Name: "NO_PROXY", Value: "registry.example.com"},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := envWithProxy(tc.proxy, tc.config, nil, tc.noproxy)
+			var noProxy *corev1.EnvVar
+			for i := range env {
+				if env[i].Name == "NO_PROXY" {
+					noProxy = &env[i]
+				}
+			}
+			assert.Equal(t, tc.expectedNoProxy, noProxy)
+		})
+	}
+}
+
+func TestNewMetal3Deployment(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	tCases := []struct {
+		name             string
+		config           *metal3iov1alpha1.ProvisioningSpec
+		singleNode       bool
+		expectedReplicas int32
+		expectedStrategy appsv1.DeploymentStrategyType
+		expectedSurge    bool
+	}{
+		{
+			name:             "default replicas",
+			config:           managedProvisioning().build(),
+			expectedReplicas: 1,
+			expectedStrategy: appsv1.RecreateDeploymentStrategyType,
+		},
+		{
+			name:             "single replica with rolling update",
+			config:           managedProvisioning().DeploymentStrategy(metal3iov1alpha1.DeploymentStrategyRollingUpdate).build(),
+			expectedReplicas: 1,
+			expectedStrategy: appsv1.RollingUpdateDeploymentStrategyType,
+			expectedSurge:    true,
+		},
+		{
+			// The surge pod could never be scheduled on a single node
+			name:             "single node with rolling update",
+			config:           managedProvisioning().DeploymentStrategy(metal3iov1alpha1.DeploymentStrategyRollingUpdate).build(),
+			singleNode:       true,
+			expectedReplicas: 1,
+			expectedStrategy: appsv1.RecreateDeploymentStrategyType,
+		},
+		{
+			name:             "paused",
+			config:           managedProvisioning().Paused(true).build(),
+			expectedReplicas: 0,
+			expectedStrategy: appsv1.RecreateDeploymentStrategyType,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Client:             fakekube.NewSimpleClientset(),
+				Images:             &images,
+				ProvConfig:         &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+				Namespace:          "openshift-machine-api",
+				SingleNodeTopology: tc.singleNode,
+			}
+			deployment, err := newMetal3Deployment(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedReplicas, *deployment.Spec.Replicas)
+			assert.Equal(t, tc.expectedStrategy, deployment.Spec.Strategy.Type)
+			assert.Equal(t, tc.expectedSurge, Metal3DeploymentSurges(info))
+			if tc.expectedSurge {
+				assert.Equal(t, intstr.FromInt(1), *deployment.Spec.Strategy.RollingUpdate.MaxSurge)
+				assert.Equal(t, intstr.FromInt(0), *deployment.Spec.Strategy.RollingUpdate.MaxUnavailable)
+			}
+			assert.Nil(t, deployment.Spec.Template.Spec.Affinity)
+		})
+	}
+}
+
+func TestMetal3DeploymentTargetNamespace(t *testing.T) {
+	const customNamespace = "custom-metal3"
+	deployment := func(namespace string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      baremetalDeploymentName,
+				Namespace: namespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"k8s-app": metal3AppName},
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+	}
+	kubeClient := fakekube.NewSimpleClientset(deployment(customNamespace), deployment(testNamespace))
+	info := &ProvisioningInfo{
+		Client:     kubeClient,
+		Images:     &Images{},
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+		Namespace:  customNamespace,
+	}
+
+	newDeployment, err := newMetal3Deployment(info)
+	assert.NoError(t, err)
+	assert.Equal(t, customNamespace, newDeployment.Namespace)
+
+	selector, err := getMetal3DeploymentSelector(info)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"k8s-app": metal3AppName}, selector.MatchLabels)
+
+	state, err := GetDeploymentState(info)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DeploymentAvailable, state)
+
+	// The stale-selector path must only delete the deployment in the
+	// target namespace.
+	assert.NoError(t, DeleteMetal3Deployment(info))
+	_, err = kubeClient.AppsV1().Deployments(customNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = kubeClient.AppsV1().Deployments(testNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	info.Namespace = ""
+	_, err = newMetal3Deployment(info)
+	assert.Error(t, err)
+	_, err = GetDeploymentState(info)
+	assert.Error(t, err)
+	assert.Error(t, DeleteMetal3Deployment(info))
+}
+
+func TestGetDeploymentStatusInitContainerFailure(t *testing.T) {
+	defer func(startTime time.Time) {
+		deploymentRolloutStartTime = startTime
+	}(deploymentRolloutStartTime)
+	deploymentRolloutStartTime = time.Now()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baremetalDeploymentName,
+			Namespace: testNamespace,
+		},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metal3-12345",
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				"k8s-app":    metal3AppName,
+				cboLabelName: stateService,
+			},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "machine-os-images",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+				},
+				{
+					Name:  "metal3-machine-os-downloader",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+				},
+			},
+		},
+	}
+	info := &ProvisioningInfo{
+		Client:    fakekube.NewSimpleClientset(deployment, pod),
+		Namespace: testNamespace,
+	}
+
+	status, err := GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DeploymentProgressing, status.State)
+	assert.Equal(t, "0/1 replicas ready, init container metal3-machine-os-downloader is waiting: ImagePullBackOff", status.Message)
+
+	// Init containers still starting are not reported
+	pod.Status.InitContainerStatuses[1].State.Waiting.Reason = "PodInitializing"
+	info.Client = fakekube.NewSimpleClientset(deployment, pod)
+	status, err = GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, "0/1 replicas ready", status.Message)
+}
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestMetal3ServiceAccount(t *testing.T) {
+	existing := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "metal3", Namespace: testNamespace},
+	}
+	tCases := []struct {
+		name          string
+		config        *metal3iov1alpha1.ProvisioningSpec
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:          "default",
+			config:        managedProvisioning().build(),
+			expectedName:  "cluster-baremetal-operator",
+			expectedError: "service account cluster-baremetal-operator not found in namespace " + testNamespace,
+		},
+		{
+			name:         "override",
+			config:       managedProvisioning().ServiceAccountName("metal3").build(),
+			expectedName: "metal3",
+		},
+		{
+			name:          "missing override",
+			config:        managedProvisioning().ServiceAccountName("metal3-missing").build(),
+			expectedName:  "metal3-missing",
+			expectedError: "service account metal3-missing not found in namespace " + testNamespace,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Client:     fakekube.NewSimpleClientset(existing),
+				Images:     &Images{},
+				Namespace:  testNamespace,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, tc.expectedName, template.Spec.ServiceAccountName)
+
+			err := CheckMetal3ServiceAccount(info)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRenderMetal3Deployment(t *testing.T) {
+	golden := filepath.Join("testdata", "metal3-deployment.yaml")
+	info := &ProvisioningInfo{
+		Client: fakekube.NewSimpleClientset(),
+		Images: &Images{
+			BaremetalOperator:   expectedBaremetalOperator,
+			Ironic:              expectedIronic,
+			MachineOsDownloader: expectedMachineOsDownloader,
+			StaticIpManager:     expectedIronicStaticIpManager,
+		},
+		ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+		Namespace:    "openshift-machine-api",
+		NetworkStack: NetworkStackV4,
+		SSHKey:       "sshkey",
+	}
+
+	rendered, err := RenderMetal3Deployment(info)
+	assert.NoError(t, err)
+	if *updateGolden {
+		assert.NoError(t, os.WriteFile(golden, rendered, 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(rendered), "run the test with -update to regenerate %s", golden)
+
+	again, err := RenderMetal3Deployment(info)
+	assert.NoError(t, err)
+	assert.Equal(t, rendered, again)
+}
+
+func TestMetal3ContainerOrder(t *testing.T) {
+	golden := filepath.Join("testdata", "metal3-container-order.yaml")
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	type containerOrder struct {
+		Name           string   `json:"name"`
+		InitContainers []string `json:"initContainers"`
+		Containers     []string `json:"containers"`
+	}
+	names := func(containers []corev1.Container) []string {
+		result := []string{}
+		for _, container := range containers {
+			result = append(result, container.Name)
+		}
+		return result
+	}
+
+	tCases := []struct {
+		name   string
+		config *metal3iov1alpha1.ProvisioningSpec
+	}{
+		{
+			name:   "managed",
+			config: managedProvisioning().build(),
+		},
+		{
+			name:   "managed with live ISO",
+			config: configWithPreProvisioningOSDownloadURLs().build(),
+		},
+		{
+			name:   "unmanaged",
+			config: unmanagedProvisioning().build(),
+		},
+		{
+			name:   "disabled",
+			config: disabledProvisioning().build(),
+		},
+		{
+			name:   "disabled with live ISO",
+			config: disabledProvisioning().PreProvisioningOSDownloadURLs(configWithPreProvisioningOSDownloadURLs().ProvisioningSpec.PreProvisioningOSDownloadURLs).build(),
+		},
+		{
+			name:   "all optional containers",
+			config: managedProvisioning().CheckProvisioningInterface().PrePullIronicImage().build(),
+		},
+	}
+	orders := []containerOrder{}
+	for _, tc := range tCases {
+		info := &ProvisioningInfo{
+			Images:     &images,
+			ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+		}
+		order := containerOrder{
+			Name:           tc.name,
+			InitContainers: names(newMetal3InitContainers(info)),
+			Containers:     names(newMetal3Containers(info)),
+		}
+		assert.IsIncreasing(t, order.Containers, tc.name)
+		orders = append(orders, order)
+	}
+
+	rendered, err := yaml.Marshal(orders)
+	assert.NoError(t, err)
+	if *updateGolden {
+		assert.NoError(t, os.WriteFile(golden, rendered, 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(rendered), "run the test with -update to regenerate %s", golden)
+}
+
+func TestGetDeploymentStatusCrashLoopBackOff(t *testing.T) {
+	defer func(startTime time.Time) {
+		deploymentRolloutStartTime = startTime
+	}(deploymentRolloutStartTime)
+	deploymentRolloutStartTime = time.Now()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baremetalDeploymentName,
+			Namespace: testNamespace,
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(1)},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metal3-12345",
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				"k8s-app":    metal3AppName,
+				cboLabelName: stateService,
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "metal3-httpd",
+					Ready: true,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+				{
+					Name:  ironicContainerName,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+					},
+				},
+			},
+		},
+	}
+	info := &ProvisioningInfo{
+		Client:    fakekube.NewSimpleClientset(deployment, pod),
+		Namespace: testNamespace,
+	}
+
+	status, err := GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, DeploymentCrashLoopBackOff, status.State)
+	assert.Equal(t, "1/1 replicas ready, container metal3-ironic is in CrashLoopBackOff, last exited with code 1: Error", status.Message)
+
+	// Once restarted, the container is no longer reported
+	pod.Status.ContainerStatuses[1].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	info.Client = fakekube.NewSimpleClientset(deployment, pod)
+	status, err = GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DeploymentAvailable, status.State)
+	assert.Equal(t, "1/1 replicas ready", status.Message)
+}
+
+func TestGetDeploymentStatusTerminationStuck(t *testing.T) {
+	defer func(startTime time.Time) {
+		deploymentRolloutStartTime = startTime
+	}(deploymentRolloutStartTime)
+	deploymentRolloutStartTime = time.Now()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baremetalDeploymentName,
+			Namespace: testNamespace,
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(1)},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	labels := map[string]string{
+		"k8s-app":    metal3AppName,
+		cboLabelName: stateService,
+	}
+	deletionTimestamp := metav1.NewTime(time.Now().Add(-podTerminationStuckThreshold - time.Minute))
+	oldPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "metal3-old",
+			Namespace:         testNamespace,
+			Labels:            labels,
+			DeletionTimestamp: &deletionTimestamp,
+		},
+		Spec: corev1.PodSpec{NodeName: "master-0"},
+	}
+	newPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metal3-new",
+			Namespace: testNamespace,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	info := &ProvisioningInfo{
+		Client:    fakekube.NewSimpleClientset(deployment, oldPod, newPod),
+		Namespace: testNamespace,
+	}
+
+	status, err := GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, DeploymentTerminationStuck, status.State)
+	assert.Equal(t, fmt.Sprintf("0/1 replicas ready, old pod metal3-old is stuck terminating on node master-0 since %s, check that the node is reachable or force delete the pod",
+		deletionTimestamp.UTC().Format(time.RFC3339)), status.Message)
+
+	// Within the threshold, the old pod is still expected to go away
+	deletionTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+	oldPod.DeletionTimestamp = &deletionTimestamp
+	info.Client = fakekube.NewSimpleClientset(deployment, oldPod, newPod)
+	status, err = GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DeploymentProgressing, status.State)
+	assert.Equal(t, "0/1 replicas ready", status.Message)
+}
+
+func TestMetal3DeploymentMinReadySeconds(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	for _, minReadySeconds := range []int32{0, 30} {
+		info := &ProvisioningInfo{
+			Client:     fakekube.NewSimpleClientset(),
+			Images:     &images,
+			ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().MinReadySeconds(minReadySeconds).build()},
+			Namespace:  testNamespace,
+		}
+		deployment, err := newMetal3Deployment(info)
+		assert.NoError(t, err)
+		assert.Equal(t, minReadySeconds, deployment.Spec.MinReadySeconds)
+	}
+}
+
+func TestGetDeploymentStatusMinReadySeconds(t *testing.T) {
+	defer func(startTime time.Time) {
+		deploymentRolloutStartTime = startTime
+	}(deploymentRolloutStartTime)
+	deploymentRolloutStartTime = time.Now().Add(-deploymentRolloutTimeout - time.Minute)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baremetalDeploymentName,
+			Namespace: testNamespace,
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(1)},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	info := &ProvisioningInfo{
+		Client:    fakekube.NewSimpleClientset(deployment),
+		Namespace: testNamespace,
+	}
+	status, err := GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DeploymentReplicaFailure, status.State)
+
+	// The pod waiting for MinReadySeconds extends the rollout timeout
+	deployment.Spec.MinReadySeconds = 300
+	info.Client = fakekube.NewSimpleClientset(deployment)
+	status, err = GetDeploymentStatus(info)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DeploymentProgressing, status.State)
+}
+
+func TestNewDeploymentStatus(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	condition := func(conditionType appsv1.DeploymentConditionType, message string) appsv1.DeploymentCondition {
+		return appsv1.DeploymentCondition{
+			Type:               conditionType,
+			Status:             corev1.ConditionTrue,
+			Message:            message,
+			LastTransitionTime: transitionTime,
+		}
+	}
+	tCases := []struct {
+		name           string
+		replicas       *int32
+		readyReplicas  int32
+		conditions     []appsv1.DeploymentCondition
+		expectedStatus DeploymentStatus
+	}{
+		{
+			name: "no conditions yet",
+			expectedStatus: DeploymentStatus{
+				State:    appsv1.DeploymentProgressing,
+				Replicas: 1,
+				Message:  "0/1 replicas ready",
+			},
+		},
+		{
+			name:          "partially ready rollout",
+			replicas:      pointer.Int32Ptr(3),
+			readyReplicas: 2,
+			conditions:    []appsv1.DeploymentCondition{condition(appsv1.DeploymentProgressing, "")},
+			expectedStatus: DeploymentStatus{
+				State:         appsv1.DeploymentProgressing,
+				Progressing:   true,
+				ReadyReplicas: 2,
+				Replicas:      3,
+				Message:       "2/3 replicas ready",
+			},
+		},
+		{
+			name:          "available while rolling out",
+			replicas:      pointer.Int32Ptr(3),
+			readyReplicas: 2,
+			conditions: []appsv1.DeploymentCondition{
+				condition(appsv1.DeploymentProgressing, ""),
+				condition(appsv1.DeploymentAvailable, ""),
+			},
+			expectedStatus: DeploymentStatus{
+				State:              appsv1.DeploymentAvailable,
+				Available:          true,
+				Progressing:        true,
+				ReadyReplicas:      2,
+				Replicas:           3,
+				Message:            "2/3 replicas ready",
+				LastTransitionTime: transitionTime,
+			},
+		},
+		{
+			name:          "replica failure",
+			replicas:      pointer.Int32Ptr(2),
+			readyReplicas: 1,
+			conditions: []appsv1.DeploymentCondition{
+				condition(appsv1.DeploymentAvailable, ""),
+				condition(appsv1.DeploymentReplicaFailure, "pods \"metal3\" is forbidden"),
+			},
+			expectedStatus: DeploymentStatus{
+				State:              appsv1.DeploymentReplicaFailure,
+				Available:          true,
+				ReadyReplicas:      1,
+				Replicas:           2,
+				Message:            "1/2 replicas ready: pods \"metal3\" is forbidden",
+				LastTransitionTime: transitionTime,
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: tc.replicas},
+				Status: appsv1.DeploymentStatus{
+					ReadyReplicas: tc.readyReplicas,
+					Conditions:    tc.conditions,
+				},
+			}
+			assert.Equal(t, tc.expectedStatus, newDeploymentStatus(deployment))
+			assert.Equal(t, tc.expectedStatus.State, getDeploymentCondition(deployment))
+		})
+	}
+}
+
+func TestMigrateMetal3DeploymentSelector(t *testing.T) {
+	const customNamespace = "custom-metal3"
+	oldDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baremetalDeploymentName,
+			Namespace: customNamespace,
+			UID:       "old",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"k8s-app": metal3AppName},
+			},
+		},
+	}
+	kubeClient := fakekube.NewSimpleClientset(oldDeployment.DeepCopy())
+	recorder := events.NewInMemoryRecorder("tests")
+	info := &ProvisioningInfo{
+		Client:        kubeClient,
+		EventRecorder: recorder,
+		Images:        &Images{},
+		ProvConfig:    &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+		Namespace:     customNamespace,
+	}
+	desired, err := newMetal3Deployment(info)
+	assert.NoError(t, err)
+
+	migrated, err := migrateMetal3DeploymentSelector(info, desired.Spec.Selector)
+	assert.NoError(t, err)
+	assert.True(t, migrated)
+	_, err = kubeClient.AppsV1().Deployments(customNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Len(t, recorder.Events(), 1)
+	assert.Equal(t, "Metal3SelectorMigration", recorder.Events()[0].Reason)
+	assert.Contains(t, recorder.Events()[0].Message, "custom-metal3/metal3 to change its selector from k8s-app=metal3 to baremetal.openshift.io/cluster-baremetal-operator=metal3-state,k8s-app=metal3")
+
+	// Nothing to migrate once the deployment is gone or recreated with the
+	// desired selector, however many times it is checked
+	migrated, err = migrateMetal3DeploymentSelector(info, desired.Spec.Selector)
+	assert.NoError(t, err)
+	assert.False(t, migrated)
+	_, err = kubeClient.AppsV1().Deployments(customNamespace).Create(context.Background(), desired, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		migrated, err = migrateMetal3DeploymentSelector(info, desired.Spec.Selector)
+		assert.NoError(t, err)
+		assert.False(t, migrated)
+	}
+	_, err = kubeClient.AppsV1().Deployments(customNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Events(), 1)
+}
+
+func TestMigrateMetal3DeploymentSelectorDeleting(t *testing.T) {
+	now := metav1.Now()
+	kubeClient := fakekube.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              baremetalDeploymentName,
+			Namespace:         testNamespace,
+			DeletionTimestamp: &now,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"k8s-app": metal3AppName},
+			},
+		},
+	})
+	deletes := 0
+	kubeClient.PrependReactor("delete", "deployments", func(action faketesting.Action) (bool, runtime.Object, error) {
+		deletes++
+		return true, nil, nil
+	})
+	recorder := events.NewInMemoryRecorder("tests")
+	info := &ProvisioningInfo{
+		Client:        kubeClient,
+		EventRecorder: recorder,
+		Images:        &Images{},
+		ProvConfig:    &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+		Namespace:     testNamespace,
+	}
+	desired, err := newMetal3Deployment(info)
+	assert.NoError(t, err)
+
+	// A deployment already being deleted is waited for, not deleted again
+	migrated, err := migrateMetal3DeploymentSelector(info, desired.Spec.Selector)
+	assert.NoError(t, err)
+	assert.True(t, migrated)
+	assert.Equal(t, 0, deletes)
+	assert.Empty(t, recorder.Events())
+}
+
+func TestMigrateMetal3DeploymentSelectorDeleteFailure(t *testing.T) {
+	kubeClient := fakekube.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baremetalDeploymentName,
+			Namespace: testNamespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"k8s-app": metal3AppName},
+			},
+		},
+	})
+	kubeClient.PrependReactor("delete", "deployments", func(action faketesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("delete failed")
+	})
+	recorder := events.NewInMemoryRecorder("tests")
+	info := &ProvisioningInfo{
+		Client:        kubeClient,
+		EventRecorder: recorder,
+		Images:        &Images{},
+		ProvConfig:    &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+		Namespace:     testNamespace,
+	}
+	desired, err := newMetal3Deployment(info)
+	assert.NoError(t, err)
+
+	migrated, err := migrateMetal3DeploymentSelector(info, desired.Spec.Selector)
+	assert.ErrorContains(t, err, "delete failed")
+	assert.False(t, migrated)
+	assert.Empty(t, recorder.Events())
+}
+
+func TestNewMetal3PodTemplateSpecScheduling(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	infraAffinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      "node-role.kubernetes.io/infra",
+								Operator: corev1.NodeSelectorOpExists,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	tCases := []struct {
+		name                 string
+		config               *metal3iov1alpha1.ProvisioningSpec
+		singleNode           bool
+		expectedNodeSelector map[string]string
+		expectedAffinity     *corev1.Affinity
+	}{
+		{
+			name:                 "default",
+			config:               managedProvisioning().build(),
+			expectedNodeSelector: map[string]string{"node-role.kubernetes.io/master": ""},
+		},
+		{
+			name:                 "custom node selector",
+			config:               managedProvisioning().NodeSelector(map[string]string{"node-role.kubernetes.io/infra": ""}).build(),
+			expectedNodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+		},
+		{
+			name:             "custom affinity",
+			config:           managedProvisioning().Affinity(infraAffinity).build(),
+			expectedAffinity: infraAffinity,
+		},
+		{
+			name:       "single node",
+			config:     managedProvisioning().build(),
+			singleNode: true,
+		},
+		{
+			name:                 "single node with custom node selector",
+			config:               managedProvisioning().NodeSelector(map[string]string{"node-role.kubernetes.io/infra": ""}).build(),
+			singleNode:           true,
+			expectedNodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:             &images,
+				ProvConfig:         &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+				SingleNodeTopology: tc.singleNode,
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, tc.expectedNodeSelector, template.Spec.NodeSelector)
+			assert.Equal(t, tc.expectedAffinity, template.Spec.Affinity)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecHostPID(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	for _, hostPID := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hostPID=%v", hostPID), func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().HostPID(hostPID).build()},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, hostPID, template.Spec.HostPID)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecSeccompProfile(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	for _, disabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("disableSeccompProfile=%v", disabled), func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().DisableSeccompProfile(disabled).build()},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.False(t, *template.Spec.SecurityContext.RunAsNonRoot)
+			if disabled {
+				assert.Nil(t, template.Spec.SecurityContext.SeccompProfile)
+			} else {
+				assert.Equal(t, &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}, template.Spec.SecurityContext.SeccompProfile)
+			}
+
+			// Privileged containers keep their own settings
+			privileged := 0
+			for _, container := range append(template.Spec.InitContainers, template.Spec.Containers...) {
+				if container.SecurityContext == nil || container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged {
+					continue
+				}
+				privileged++
+				assert.Nil(t, container.SecurityContext.SeccompProfile, "container %s", container.Name)
+			}
+			assert.NotZero(t, privileged)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecTerminationGracePeriod(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected int64
+	}{
+		{
+			name:     "default",
+			config:   managedProvisioning().build(),
+			expected: 120,
+		},
+		{
+			name:     "custom",
+			config:   managedProvisioning().TerminationGracePeriodSeconds(600).build(),
+			expected: 600,
+		},
+		{
+			name:     "immediate",
+			config:   managedProvisioning().TerminationGracePeriodSeconds(0).build(),
+			expected: 0,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, tc.expected, *template.Spec.TerminationGracePeriodSeconds)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecPriorityClassName(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected string
+	}{
+		{
+			name:     "default",
+			config:   managedProvisioning().build(),
+			expected: "system-node-critical",
+		},
+		{
+			name:     "override",
+			config:   managedProvisioning().PriorityClassName("openshift-user-critical").build(),
+			expected: "openshift-user-critical",
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, tc.expected, template.Spec.PriorityClassName)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecDNS(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	dnsConfig := &corev1.PodDNSConfig{
+		Nameservers: []string{"192.168.111.1"},
+		Searches:    []string{"mirror.example.com"},
+	}
+	tCases := []struct {
+		name           string
+		config         *metal3iov1alpha1.ProvisioningSpec
+		expectedPolicy corev1.DNSPolicy
+		expectedConfig *corev1.PodDNSConfig
+	}{
+		{
+			name:           "default",
+			config:         managedProvisioning().build(),
+			expectedPolicy: corev1.DNSClusterFirstWithHostNet,
+		},
+		{
+			name:           "config only",
+			config:         managedProvisioning().DNS("", dnsConfig).build(),
+			expectedPolicy: corev1.DNSClusterFirstWithHostNet,
+			expectedConfig: dnsConfig,
+		},
+		{
+			name:           "policy override",
+			config:         managedProvisioning().DNS(corev1.DNSNone, dnsConfig).build(),
+			expectedPolicy: corev1.DNSNone,
+			expectedConfig: dnsConfig,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, tc.expectedPolicy, template.Spec.DNSPolicy)
+			assert.Equal(t, tc.expectedConfig, template.Spec.DNSConfig)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecImagePullSecrets(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected []corev1.LocalObjectReference
+	}{
+		{
+			name:   "default",
+			config: managedProvisioning().build(),
+		},
+		{
+			name:     "configured",
+			config:   managedProvisioning().ImagePullSecrets("registry-a", "registry-b").build(),
+			expected: []corev1.LocalObjectReference{{Name: "registry-a"}, {Name: "registry-b"}},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, tc.expected, template.Spec.ImagePullSecrets)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecImagePullPolicy(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	tCases := []struct {
+		name           string
+		pullPolicy     corev1.PullPolicy
+		expectedPolicy corev1.PullPolicy
+	}{
+		{
+			name:           "default",
+			expectedPolicy: corev1.PullIfNotPresent,
+		},
+		{
+			name:           "always",
+			pullPolicy:     corev1.PullAlways,
+			expectedPolicy: corev1.PullAlways,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().ImagePullPolicy(tc.pullPolicy).build()},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			for _, container := range append(template.Spec.InitContainers, template.Spec.Containers...) {
+				assert.Equal(t, tc.expectedPolicy, container.ImagePullPolicy, "container name: ", container.Name)
+			}
+		})
+	}
+}
+
+func TestNewMetal3ContainersSharedVolumeReadOnly(t *testing.T) {
+	info := &ProvisioningInfo{
+		Images:     &Images{Ironic: expectedIronic},
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+	}
+	expectedReadOnly := map[string]bool{
+		"metal3-httpd":        false,
+		"metal3-ironic":       false,
+		"metal3-ramdisk-logs": true,
+	}
+	for _, container := range newMetal3Containers(info) {
+		readOnly, ok := expectedReadOnly[container.Name]
+		if !ok {
+			continue
+		}
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == baremetalSharedVolume && mount.MountPath == "/shared" {
+				assert.Equal(t, readOnly, mount.ReadOnly, "container name: ", container.Name)
+			}
+		}
+	}
+}
+
+func TestNewMetal3PodTemplateSpecTokenAudience(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	findVolume := func(volumes []corev1.Volume) *corev1.Volume {
+		for i := range volumes {
+			if volumes[i].Name == ironicTokenVolume {
+				return &volumes[i]
+			}
+		}
+		return nil
+	}
+
+	info := &ProvisioningInfo{
+		Images:     &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+	}
+	template := newMetal3PodTemplateSpec(info, &map[string]string{})
+	assert.Nil(t, findVolume(template.Spec.Volumes))
+
+	info.ProvConfig.Spec.ServiceAccountTokenAudience = "ironic.metal3.io"
+	template = newMetal3PodTemplateSpec(info, &map[string]string{})
+	volume := findVolume(template.Spec.Volumes)
+	assert.NotNil(t, volume)
+	token := volume.Projected.Sources[0].ServiceAccountToken
+	assert.Equal(t, "ironic.metal3.io", token.Audience)
+	assert.Equal(t, int64(3600), *token.ExpirationSeconds)
+	for _, container := range template.Spec.Containers {
+		if container.Name == ironicContainerName {
+			assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{
+				Name:      ironicTokenVolume,
+				MountPath: ironicTokenMountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+	// The shared volume list must not be modified
+	assert.Nil(t, findVolume(metal3Volumes))
+}
+
+func TestNewMetal3VolumesSharedVolumeMedium(t *testing.T) {
+	findSharedVolume := func(volumes []corev1.Volume) *corev1.Volume {
+		for i := range volumes {
+			if volumes[i].Name == baremetalSharedVolume {
+				return &volumes[i]
+			}
+		}
+		return nil
+	}
+
+	volume := findSharedVolume(newMetal3Volumes(managedProvisioning().build()))
+	assert.Equal(t, &corev1.EmptyDirVolumeSource{}, volume.EmptyDir)
+
+	sizeLimit := resource.MustParse("8Gi")
+	config := managedProvisioning().SharedVolumeMedium(metal3iov1alpha1.SharedVolumeMediumDisk, &sizeLimit).build()
+	volume = findSharedVolume(newMetal3Volumes(config))
+	assert.Equal(t, &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}, volume.EmptyDir)
+
+	config = managedProvisioning().SharedVolumeMedium(metal3iov1alpha1.SharedVolumeMediumMemory, &sizeLimit).build()
+	volume = findSharedVolume(newMetal3Volumes(config))
+	assert.Equal(t, &corev1.EmptyDirVolumeSource{
+		Medium:    corev1.StorageMediumMemory,
+		SizeLimit: &sizeLimit,
+	}, volume.EmptyDir)
+	// The shared volume list must not be modified
+	assert.Equal(t, &corev1.EmptyDirVolumeSource{}, findSharedVolume(metal3Volumes).EmptyDir)
+}
+
+func TestNewMetal3VolumesAdditionalTrustBundle(t *testing.T) {
+	findTrustedCAVolume := func(volumes []corev1.Volume) *corev1.Volume {
+		for i := range volumes {
+			if volumes[i].Name == "trusted-ca" {
+				return &volumes[i]
+			}
+		}
+		return nil
+	}
+
+	volume := findTrustedCAVolume(newMetal3Volumes(managedProvisioning().build()))
+	assert.Equal(t, trustedCAVolume(), *volume)
+
+	config := managedProvisioning().AdditionalTrustBundleConfigMap("registry-ca").build()
+	for _, volumes := range [][]corev1.Volume{
+		newMetal3Volumes(config),
+		withTrustedCAVolume(bmoVolumes, config),
+	} {
+		volume = findTrustedCAVolume(volumes)
+		assert.Nil(t, volume.ConfigMap)
+		sources := volume.Projected.Sources
+		assert.Len(t, sources, 2)
+		assert.Equal(t, "cbo-trusted-ca", sources[0].ConfigMap.Name)
+		assert.Equal(t, []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}}, sources[0].ConfigMap.Items)
+		assert.True(t, *sources[0].ConfigMap.Optional)
+		assert.Equal(t, "registry-ca", sources[1].ConfigMap.Name)
+		assert.Equal(t, []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "additional-ca-bundle.pem"}}, sources[1].ConfigMap.Items)
+		assert.True(t, *sources[1].ConfigMap.Optional)
+	}
+	// The shared volume lists must not be modified
+	assert.Equal(t, trustedCAVolume(), *findTrustedCAVolume(metal3Volumes))
+	assert.Equal(t, trustedCAVolume(), *findTrustedCAVolume(bmoVolumes))
+}
+
+func TestNewMetal3PodTemplateSpecHostPorts(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	for _, disableHostPorts := range []bool{false, true} {
+		t.Run(fmt.Sprintf("disableHostPorts=%v", disableHostPorts), func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().DisableHostPorts(disableHostPorts).build()},
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.True(t, template.Spec.HostNetwork)
+			hostPorts := 0
+			for _, container := range template.Spec.Containers {
+				for _, port := range container.Ports {
+					assert.NotZero(t, port.ContainerPort)
+					if port.HostPort != 0 {
+						hostPorts++
+					}
+				}
+			}
+			if disableHostPorts {
+				assert.Zero(t, hostPorts)
+			} else {
+				assert.NotZero(t, hostPorts)
+			}
+		})
+	}
+}
+
+func TestNewMetal3ContainersHTTPPort(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	info := &ProvisioningInfo{
+		Images:     &images,
+		Namespace:  testNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().HTTPPort(8180).build()},
+	}
+
+	// httpd serves on the port set in the environment of all the containers
+	for _, container := range newMetal3Containers(info) {
+		for _, env := range container.Env {
+			if env.Name == httpPort {
+				assert.Equal(t, "8180", env.Value, container.Name)
+			}
+		}
+		if container.Name == "metal3-httpd" {
+			assert.Contains(t, container.Ports, corev1.ContainerPort{Name: httpPortName, ContainerPort: 8180, HostPort: 8180})
+		}
+	}
+
+	service := newMetal3StateService(info)
+	assert.Contains(t, service.Spec.Ports, corev1.ServicePort{Name: httpPortName, Port: 8180})
+
+	cacheURL, err := transformURL(testNamespace, getHttpPort(&info.ProvConfig.Spec), "http://example.com/rhcos.qcow2.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://metal3-state."+testNamespace+".svc.cluster.local:8180/images/rhcos.qcow2/rhcos.qcow2", cacheURL)
+}
+
+func TestNewMetal3ContainersPortOverrides(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	info := &ProvisioningInfo{
+		Images:     &images,
+		Namespace:  testNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().IronicPort(7385).InspectorPort(7050).JSONRPCPort(7089).build()},
+	}
+
+	envValue := func(container corev1.Container, name string) string {
+		for _, env := range container.Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+		return ""
+	}
+	for _, container := range newMetal3Containers(info) {
+		switch container.Name {
+		case "metal3-httpd":
+			assert.Contains(t, container.Ports, corev1.ContainerPort{Name: "ironic", ContainerPort: 7385, HostPort: 7385})
+			assert.Contains(t, container.Ports, corev1.ContainerPort{Name: "inspector", ContainerPort: 7050, HostPort: 7050})
+			assert.Equal(t, "7385", envValue(container, ironicListenPortEnvVar))
+			assert.Equal(t, "7050", envValue(container, inspectorListenPortEnvVar))
+		case "metal3-ironic":
+			assert.Equal(t, "7089", envValue(container, ironicRPCPortEnvVar))
+			assert.Equal(t, "https://172.30.20.3:7385", envValue(container, ironicCallbackUrl))
+		}
+	}
+
+	service := newMetal3StateService(info)
+	assert.Contains(t, service.Spec.Ports, corev1.ServicePort{Name: "ironic", Port: 7385})
+	assert.Contains(t, service.Spec.Ports, corev1.ServicePort{Name: "inspector", Port: 7050})
+
+	ironicURL, inspectorURL := getControlPlaneEndpoints(info)
+	assert.Equal(t, "https://metal3-state."+testNamespace+".svc.cluster.local:7385/v1/", ironicURL)
+	assert.Equal(t, "https://metal3-state."+testNamespace+".svc.cluster.local:7050/v1/", inspectorURL)
+
+	// In the proxy mode, ironic-proxy serves on the overridden ports
+	proxy := createContainerIronicProxy("192.168.111.5", &images, &info.ProvConfig.Spec)
+	assert.Equal(t, []corev1.ContainerPort{
+		{Name: "ironic-proxy", ContainerPort: 7385, HostPort: 7385},
+		{Name: "inspector-proxy", ContainerPort: 7050, HostPort: 7050},
+	}, proxy.Ports)
+	assert.Equal(t, "7385", envValue(proxy, ironicProxyPortEnvVar))
+	assert.Equal(t, "7050", envValue(proxy, inspectorProxyPortEnvVar))
+}
+
+func TestNewMetal3PodTemplateSpecInitContainerEnv(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	retries := corev1.EnvVar{Name: "CURL_RETRIES", Value: "10"}
+	info := &ProvisioningInfo{
+		Images: &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().
+			ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").
+			InitContainerEnv("metal3-machine-os-downloader", retries, corev1.EnvVar{Name: "RHCOS_IMAGE_URL", Value: "http://ignored"}).
+			build()},
+	}
+	template := newMetal3PodTemplateSpec(info, &map[string]string{})
+
+	found := false
+	for _, container := range template.Spec.InitContainers {
+		if container.Name != "metal3-machine-os-downloader" {
+			assert.NotContains(t, container.Env, retries, "container name: ", container.Name)
+			continue
+		}
+		found = true
+		assert.Contains(t, container.Env, retries)
+		imageURLs := 0
+		for _, env := range container.Env {
+			if env.Name == "RHCOS_IMAGE_URL" {
+				imageURLs++
+				assert.NotEqual(t, "http://ignored", env.Value)
+			}
+		}
+		assert.Equal(t, 1, imageURLs)
+	}
+	assert.True(t, found)
+	for _, container := range template.Spec.Containers {
+		assert.NotContains(t, container.Env, retries, "container name: ", container.Name)
+	}
+}
+
+func TestNewMetal3PodTemplateSpecTolerations(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	dedicated := corev1.Toleration{
+		Key:      "dedicated",
+		Value:    "baremetal",
+		Effect:   corev1.TaintEffectNoSchedule,
+		Operator: corev1.TolerationOpEqual,
+	}
+	master := corev1.Toleration{
+		Key:      "node-role.kubernetes.io/master",
+		Effect:   corev1.TaintEffectNoSchedule,
+		Operator: corev1.TolerationOpExists,
+	}
+
+	defaultInfo := &ProvisioningInfo{
+		Images:     &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+	}
+	defaultTolerations := newMetal3PodTemplateSpec(defaultInfo, &map[string]string{}).Spec.Tolerations
+
+	info := &ProvisioningInfo{
+		Images:     &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().AdditionalTolerations(dedicated, master).build()},
+	}
+	tolerations := newMetal3PodTemplateSpec(info, &map[string]string{}).Spec.Tolerations
+
+	assert.Contains(t, tolerations, dedicated)
+	assert.Len(t, tolerations, len(defaultTolerations)+1, "the built-in master toleration must not be duplicated")
+}
+
+func TestNewIronicStartupProbe(t *testing.T) {
+	tCases := []struct {
+		name            string
+		config          *metal3iov1alpha1.ProvisioningSpec
+		expectedCommand string
+	}{
+		{
+			name:            "ManagedSpec",
+			config:          managedProvisioning().build(),
+			expectedCommand: "curl -sSfk https://127.0.0.1:6385",
+		},
+		{
+			name:            "DisabledSpec",
+			config:          disabledProvisioning().build(),
+			expectedCommand: "curl -sSfk https://127.0.0.1:6388",
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &Images{Ironic: expectedIronic},
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			container := createContainerMetal3Ironic(info.Images, info, &info.ProvConfig.Spec, "")
+			probe := container.StartupProbe
+			assert.NotNil(t, probe)
+			assert.Equal(t, []string{"sh", "-c", tc.expectedCommand}, probe.Exec.Command)
+			assert.Equal(t, int32(10), probe.PeriodSeconds)
+			assert.Equal(t, int32(60), probe.FailureThreshold)
+			assert.Equal(t, 2*deploymentRolloutTimeout, time.Duration(probe.PeriodSeconds*probe.FailureThreshold)*time.Second)
+		})
+	}
+}
+
+func TestNewMetal3PodTemplateSpecExtraHostPathVolumes(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	config := managedProvisioning().ExtraHostPathVolumes(metal3iov1alpha1.HostPathVolume{
+		Name:       "tftp-cache",
+		HostPath:   "/var/lib/tftpboot",
+		MountPath:  "/debug/tftpboot",
+		Containers: []string{"metal3-ironic", "metal3-dnsmasq"},
+	}).build()
+	info := &ProvisioningInfo{
+		Images:     &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *config},
+	}
+
+	template := newMetal3PodTemplateSpec(info, &map[string]string{})
+
+	assert.Contains(t, template.Spec.Volumes, corev1.Volume{
+		Name: "host-tftp-cache",
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{Path: "/var/lib/tftpboot"},
+		},
+	})
+	expectedMount := corev1.VolumeMount{Name: "host-tftp-cache", MountPath: "/debug/tftpboot", ReadOnly: true}
+	for _, container := range template.Spec.Containers {
+		if container.Name == "metal3-ironic" || container.Name == "metal3-dnsmasq" {
+			assert.Contains(t, container.VolumeMounts, expectedMount, container.Name)
+		} else {
+			assert.NotContains(t, container.VolumeMounts, expectedMount, container.Name)
+		}
+	}
+}
+
+func TestNewMetal3VolumesExternalTLSSecret(t *testing.T) {
+	tlsVolumes := map[string]bool{ironicTlsVolume: true, inspectorTlsVolume: true, vmediaTlsVolume: true}
+	secretNames := func(volumes []corev1.Volume) map[string]string {
+		names := map[string]string{}
+		for _, volume := range volumes {
+			if tlsVolumes[volume.Name] {
+				names[volume.Name] = volume.Secret.SecretName
+			}
+		}
+		return names
+	}
+
+	config := managedProvisioning().ExternalTLSSecret("ironic-cert").build()
+	assert.Equal(t, map[string]string{
+		ironicTlsVolume:    "ironic-cert",
+		inspectorTlsVolume: "ironic-cert",
+		vmediaTlsVolume:    "ironic-cert",
+	}, secretNames(newMetal3Volumes(config)))
+	assert.Equal(t, map[string]string{
+		ironicTlsVolume:    "ironic-cert",
+		inspectorTlsVolume: "ironic-cert",
+	}, secretNames(withTLSSecret(bmoVolumes, config)))
+
+	// The shared volume definitions are left untouched
+	assert.Equal(t, map[string]string{
+		ironicTlsVolume:    tlsSecretName,
+		inspectorTlsVolume: tlsSecretName,
+		vmediaTlsVolume:    tlsSecretName,
+	}, secretNames(newMetal3Volumes(managedProvisioning().build())))
+}
+
+func TestNewMetal3ContainersProbeTuning(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	tuning := &metal3iov1alpha1.ProbeTuning{
+		PeriodSeconds:    pointer.Int32Ptr(20),
+		FailureThreshold: pointer.Int32Ptr(90),
+	}
+	info := &ProvisioningInfo{
+		Images:     &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().ProbeTuning(tuning).build()},
+	}
+
+	probes := 0
+	for _, container := range newMetal3Containers(info) {
+		for _, probe := range []*corev1.Probe{container.StartupProbe, container.LivenessProbe, container.ReadinessProbe} {
+			if probe == nil {
+				continue
+			}
+			probes++
+			assert.Equal(t, int32(20), probe.PeriodSeconds, container.Name)
+			assert.Equal(t, int32(90), probe.FailureThreshold, container.Name)
+			// Fields left unset keep their defaults
+			assert.Equal(t, int32(10), probe.TimeoutSeconds, container.Name)
+			assert.Zero(t, probe.InitialDelaySeconds, container.Name)
+		}
+	}
+	assert.NotZero(t, probes)
+}
+
+func TestNewMetal3ContainersLogFormat(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	hasJSONFormat := func(container corev1.Container) bool {
+		for _, env := range container.Env {
+			if env.Name == ironicUseJSONEnvVar {
+				return env.Value == "true"
+			}
+		}
+		return false
+	}
+
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected []string
+	}{
+		{
+			name:   "default",
+			config: managedProvisioning().build(),
+		},
+		{
+			name:   "text",
+			config: managedProvisioning().LogFormat(metal3iov1alpha1.LogFormatText).build(),
+		},
+		{
+			name:     "json",
+			config:   managedProvisioning().LogFormat(metal3iov1alpha1.LogFormatJSON).build(),
+			expected: []string{"metal3-ironic", "metal3-ironic-inspector"},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &images,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			var actual []string
+			for _, container := range newMetal3Containers(info) {
+				if hasJSONFormat(container) {
+					actual = append(actual, container.Name)
+				}
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGetKernelParams(t *testing.T) {
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected string
+	}{
+		{
+			name:     "default",
+			config:   managedProvisioning().build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp",
+		},
+		{
+			name:     "extra params",
+			config:   managedProvisioning().RamdiskExtraKernelParams("console=ttyS0,115200n8", "rd.break").build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp console=ttyS0,115200n8 rd.break",
+		},
+		{
+			name:     "disabled network",
+			config:   disabledProvisioning().RamdiskExtraKernelParams("ipa-debug=1").build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp6 ipa-debug=1",
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getKernelParams(tc.config, NetworkStackV6))
+
+			info := &ProvisioningInfo{
+				Images:       &Images{},
+				ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+				NetworkStack: NetworkStackV6,
+			}
+			for _, container := range newMetal3Containers(info) {
+				for _, env := range container.Env {
+					if env.Name == ironicKernelParamsEnvVar {
+						assert.Equal(t, tc.expected, env.Value, container.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewMetal3ContainersKeepalived(t *testing.T) {
+	images := Images{
+		Ironic:          expectedIronic,
+		StaticIpManager: expectedIronicStaticIpManager,
+		Keepalived:      expectedKeepalived,
+	}
+	findKeepalived := func(config *metal3iov1alpha1.ProvisioningSpec) *corev1.Container {
+		info := &ProvisioningInfo{
+			Images:     &images,
+			ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *config},
+		}
+		for _, container := range newMetal3Containers(info) {
+			if container.Name == "metal3-keepalived" {
+				return &container
+			}
+		}
+		return nil
+	}
+
+	assert.Nil(t, findKeepalived(managedProvisioning().build()))
+	// Never advertised without a provisioning network
+	assert.Nil(t, findKeepalived(disabledProvisioning().ProvisioningVIP("172.30.20.4").build()))
+
+	container := findKeepalived(unmanagedProvisioning().ProvisioningVIP("172.30.20.4").build())
+	if assert.NotNil(t, container) {
+		assert.Equal(t, expectedKeepalived, container.Image)
+		assert.Contains(t, container.Env, corev1.EnvVar{Name: "OPENSHIFT_HA_VIRTUAL_IPS", Value: "172.30.20.4"})
+		assert.Contains(t, container.Env, corev1.EnvVar{Name: "OPENSHIFT_HA_NETWORK_INTERFACE", Value: "ensp0"})
+		assert.Contains(t, container.Env, corev1.EnvVar{Name: "OPENSHIFT_HA_MONITOR_PORT", Value: "6180"})
+	}
+}
+
+func TestNewMetal3ContainersImageOverrides(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	info := &ProvisioningInfo{
+		Images: &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			Spec: *managedProvisioning().ImageOverride("metal3-ironic-inspector", "quay.io/metal3-io/ironic:canary").build(),
+		},
+	}
+
+	containers := newMetal3Containers(info)
+	assert.NotEmpty(t, containers)
+	for _, container := range containers {
+		if container.Name == "metal3-ironic-inspector" {
+			assert.Equal(t, "quay.io/metal3-io/ironic:canary", container.Image)
+		} else {
+			assert.NotEqual(t, "quay.io/metal3-io/ironic:canary", container.Image, container.Name)
+		}
+	}
+	for _, container := range newMetal3InitContainers(info) {
+		assert.NotEqual(t, "quay.io/metal3-io/ironic:canary", container.Image, container.Name)
+	}
+}
+
+func TestNewMetal3ContainersSharedVolumePath(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	info := &ProvisioningInfo{
+		Images: &images,
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			Spec: *managedProvisioning().SharedVolumePath("/var/lib/metal3").build(),
+		},
+		NetworkStack: NetworkStackV4,
+	}
+
+	containers := append(newMetal3InitContainers(info), newMetal3Containers(info)...)
+	sharedMounts := 0
+	for _, container := range containers {
+		for _, mount := range container.VolumeMounts {
+			assert.NotEqual(t, "/shared", mount.MountPath, container.Name)
+			assert.False(t, strings.HasPrefix(mount.MountPath, "/shared/"), "%s mounts %s", container.Name, mount.MountPath)
+			if mount.Name == baremetalSharedVolume && mount.SubPath == "" {
+				assert.Equal(t, "/var/lib/metal3", mount.MountPath, container.Name)
+				sharedMounts++
+			}
+		}
+		for _, arg := range container.Command {
+			assert.False(t, strings.HasPrefix(arg, "/shared/"), "%s runs %s", container.Name, arg)
+		}
+	}
+	assert.Greater(t, sharedMounts, 1)
+
+	// The defaults shared between containers are left untouched
+	assert.Equal(t, "/shared", sharedVolumeMount.MountPath)
+	assert.Equal(t, "/shared", ramdiskLogsVolumeMounts[0].MountPath)
+
+	assert.Equal(t, "file:///var/lib/metal3/html/images/ironic-python-agent.kernel", *getDeployKernelUrl(&info.ProvConfig.Spec))
+}
+
+func TestNewMetal3ContainersExternalDHCP(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	containerNames := func(config *metal3iov1alpha1.ProvisioningSpec) []string {
+		info := &ProvisioningInfo{
+			Images:     &images,
+			ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *config},
+		}
+		names := []string{}
+		for _, container := range newMetal3Containers(info) {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+
+	tCases := []struct {
+		name   string
+		config *provisioningBuilder
+	}{
+		{
+			name:   "managed",
+			config: managedProvisioning(),
+		},
+		{
+			name:   "unmanaged",
+			config: unmanagedProvisioning(),
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			internal := containerNames(tc.config.build())
+			external := containerNames(tc.config.ExternalDHCP().build())
+			assert.Contains(t, internal, "metal3-dnsmasq")
+			assert.NotContains(t, external, "metal3-dnsmasq")
+			assert.Contains(t, external, "metal3-httpd")
+			assert.Contains(t, external, "metal3-static-ip-manager")
+			assert.Len(t, external, len(internal)-1)
+		})
+	}
+}
+
+func TestHardenSecurityContext(t *testing.T) {
+	images := Images{
+		BaremetalOperator:   expectedBaremetalOperator,
+		Ironic:              expectedIronic,
+		MachineOsDownloader: expectedMachineOsDownloader,
+		StaticIpManager:     expectedIronicStaticIpManager,
+	}
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected []string
+	}{
+		{
+			name:   "default",
+			config: managedProvisioning().CheckProvisioningInterface().build(),
+		},
+		{
+			name:   "hardened",
+			config: managedProvisioning().CheckProvisioningInterface().HardenSecurityContext(true).build(),
+			expected: []string{
+				"metal3-provisioning-interface-check",
+				"metal3-ramdisk-logs",
+				"metal3-baremetal-operator",
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Namespace:    "openshift-machine-api",
+				Images:       &images,
+				ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+				NetworkStack: NetworkStackV4,
+				Client:       fakekube.NewSimpleClientset(),
+				OSClient:     fakeconfigclientset.NewSimpleClientset(),
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			bmo, err := createContainerBaremetalOperator(info)
+			assert.NoError(t, err)
+
+			var actual []string
+			containers := append(append(template.Spec.InitContainers, template.Spec.Containers...), bmo)
+			for _, container := range containers {
+				securityContext := container.SecurityContext
+				if securityContext == nil || securityContext.AllowPrivilegeEscalation == nil {
+					continue
+				}
+				assert.False(t, *securityContext.AllowPrivilegeEscalation, container.Name)
+				assert.Nil(t, securityContext.Privileged, container.Name)
+				actual = append(actual, container.Name)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCreateContainerMetal3DnsmasqNetworkStack(t *testing.T) {
+	noCIDR := func() *metal3iov1alpha1.ProvisioningSpec {
+		spec := managedProvisioning().build()
+		spec.ProvisioningNetworkCIDR = ""
+		return spec
+	}
+	tCases := []struct {
+		name         string
+		config       *metal3iov1alpha1.ProvisioningSpec
+		networkStack NetworkStackType
+		expectedEnv  []corev1.EnvVar
+	}{
+		{
+			name:         "IPv4 provisioning network on IPv6 cluster",
+			config:       managedProvisioning().build(),
+			networkStack: NetworkStackV6,
+		},
+		{
+			name:         "IPv6 provisioning network on IPv4 cluster",
+			config:       managedIPv6Provisioning().build(),
+			networkStack: NetworkStackV4,
+			expectedEnv: []corev1.EnvVar{
+				{Name: "DNSMASQ_IP_STACK", Value: "v6"},
+				{Name: "DNSMASQ_ENABLE_RA", Value: "true"},
+			},
+		},
+		{
+			name:         "V4",
+			config:       noCIDR(),
+			networkStack: NetworkStackV4,
+		},
+		{
+			name:         "V6",
+			config:       noCIDR(),
+			networkStack: NetworkStackV6,
+			expectedEnv: []corev1.EnvVar{
+				{Name: "DNSMASQ_IP_STACK", Value: "v6"},
+				{Name: "DNSMASQ_ENABLE_RA", Value: "true"},
+			},
+		},
+		{
+			name:         "IPv6 provisioning network on dual-stack cluster",
+			config:       managedIPv6Provisioning().build(),
+			networkStack: NetworkStackDual,
+			expectedEnv: []corev1.EnvVar{
+				{Name: "DNSMASQ_IP_STACK", Value: "v6"},
+				{Name: "DNSMASQ_ENABLE_RA", Value: "true"},
+			},
+		},
+		{
+			name:         "IPv4 provisioning network on dual-stack cluster",
+			config:       managedProvisioning().build(),
+			networkStack: NetworkStackDual,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := createContainerMetal3Dnsmasq(&Images{Ironic: expectedIronic}, tc.config, tc.networkStack)
+			var stackEnv []corev1.EnvVar
+			for _, env := range container.Env {
+				if env.Name == "DNSMASQ_IP_STACK" || env.Name == "DNSMASQ_ENABLE_RA" {
+					stackEnv = append(stackEnv, env)
+				}
+			}
+			assert.Equal(t, tc.expectedEnv, stackEnv)
+		})
+	}
+}
+
+func TestDryRunMetal3Deployment(t *testing.T) {
+	kubeClient := fakekube.NewSimpleClientset()
+	info := &ProvisioningInfo{
+		Client:        kubeClient,
+		EventRecorder: events.NewInMemoryRecorder("tests"),
+		Images:        &Images{},
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName, UID: "test-uid"},
+			Spec:       *managedProvisioning().build(),
+		},
+		Namespace: testNamespace,
+		Scheme:    scheme,
+	}
+
+	// No deployment on the cluster yet
+	deployment, changed, err := DryRunMetal3Deployment(info)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Len(t, deployment.OwnerReferences, 1)
+	assert.Equal(t, metal3iov1alpha1.ProvisioningSingletonName, deployment.OwnerReferences[0].Name)
+
+	// The deployment as applied is unchanged
+	created, err := kubeClient.AppsV1().Deployments(testNamespace).Create(context.Background(), deployment, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	resourcemerge.SetDeploymentGeneration(&info.ProvConfig.Status.Generations, created)
+	kubeClient.ClearActions()
+	_, changed, err = DryRunMetal3Deployment(info)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// A configuration change is reported without being written
+	info.ProvConfig.Spec.ProvisioningNetwork = metal3iov1alpha1.ProvisioningNetworkDisabled
+	deployment, changed, err = DryRunMetal3Deployment(info)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NotEqual(t, created.Annotations, deployment.Annotations)
+
+	for _, action := range kubeClient.Actions() {
+		assert.Equal(t, "get", action.GetVerb())
+	}
+}
+
+func TestIsMetal3DeploymentConverged(t *testing.T) {
+	tCases := []struct {
+		name           string
+		replicas       *int32
+		generation     int64
+		status         appsv1.DeploymentStatus
+		expectedResult bool
+		expectedReason string
+	}{
+		{
+			name:       "converged",
+			replicas:   pointer.Int32Ptr(2),
+			generation: 3,
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 3,
+				Replicas:           2,
+				UpdatedReplicas:    2,
+				AvailableReplicas:  2,
+			},
+			expectedResult: true,
+		},
+		{
+			name:       "default replicas",
+			generation: 1,
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  1,
+			},
+			expectedResult: true,
+		},
+		{
+			name:       "observed generation lag",
+			replicas:   pointer.Int32Ptr(1),
+			generation: 4,
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 3,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  1,
+			},
+			expectedReason: "generation 4 not observed yet, last observed 3",
+		},
+		{
+			name:       "partially updated",
+			replicas:   pointer.Int32Ptr(2),
+			generation: 2,
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           2,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  2,
+			},
+			expectedReason: "1/2 replicas updated",
+		},
+		{
+			name:       "old replica terminating",
+			replicas:   pointer.Int32Ptr(1),
+			generation: 2,
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           2,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  1,
+			},
+			expectedReason: "1 old replicas pending termination",
+		},
+		{
+			name:       "partially available",
+			replicas:   pointer.Int32Ptr(3),
+			generation: 2,
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           3,
+				UpdatedReplicas:    3,
+				AvailableReplicas:  2,
+			},
+			expectedReason: "2/3 replicas available",
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       baremetalDeploymentName,
+					Namespace:  testNamespace,
+					Generation: tc.generation,
+				},
+				Spec:   appsv1.DeploymentSpec{Replicas: tc.replicas},
+				Status: tc.status,
+			}
+			converged, reason, err := IsMetal3DeploymentConverged(fakekube.NewSimpleClientset(deployment).AppsV1(), testNamespace)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, converged)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		converged, reason, err := IsMetal3DeploymentConverged(fakekube.NewSimpleClientset().AppsV1(), testNamespace)
+		assert.NoError(t, err)
+		assert.False(t, converged)
+		assert.Equal(t, "metal3 deployment not found", reason)
+	})
+}
@@ -2,8 +2,11 @@ package provisioning
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
@@ -11,6 +14,8 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -18,6 +23,8 @@ import (
 
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
 
 const (
@@ -40,6 +47,8 @@ const (
 	OpenshiftConfigNamespace = "openshift-config"
 	// PullSecretName holds the name of the pull-secret in openshift-config and openshift-machine-config.
 	PullSecretName = "pull-secret"
+
+	secretChecksumAnnotationPrefix = "checksum/"
 )
 
 type shouldUpdateDataFn func(existing *corev1.Secret) (bool, error)
@@ -187,8 +196,12 @@ func EnsureAllSecrets(info *ProvisioningInfo) (bool, error) {
 	if err := createIronicSecret(info, inspectorSecretName, inspectorUsername, "inspector"); err != nil {
 		return false, errors.Wrap(err, "failed to create Inspector password")
 	}
-	// Generate/update TLS certificate
-	if err := createOrUpdateTlsSecret(info); err != nil {
+	// Generate/update TLS certificate, unless an external one is provided
+	if info.ProvConfig.Spec.ExternalTLSSecret != "" {
+		if err := checkExternalTlsSecret(info); err != nil {
+			return false, errors.Wrap(err, "invalid external TLS certificate")
+		}
+	} else if err := createOrUpdateTlsSecret(info); err != nil {
 		return false, errors.Wrap(err, "failed to create TLS certificate")
 	}
 	// Create a Secret for the Registry Pull Secret
@@ -208,6 +221,123 @@ func DeleteAllSecrets(info *ProvisioningInfo) error {
 	return utilerrors.NewAggregate(secretErrors)
 }
 
+// getTlsSecretName returns the name of the Secret holding the Ironic and
+// Inspector TLS certificate.
+func getTlsSecretName(config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.ExternalTLSSecret != "" {
+		return config.ExternalTLSSecret
+	}
+	return tlsSecretName
+}
+
+// checkExternalTlsSecret makes sure the external TLS Secret exists and holds a
+// certificate and a private key before the pods mounting it are rendered.
+func checkExternalTlsSecret(info *ProvisioningInfo) error {
+	name := info.ProvConfig.Spec.ExternalTLSSecret
+	secret, err := info.Client.CoreV1().Secrets(info.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return fmt.Errorf("secret %s has no %s key", name, key)
+		}
+	}
+	return nil
+}
+
+// CheckImagePullSecrets makes sure the image pull secrets of the metal3 pod
+// exist, as the kubelet only reports a missing one as a pull failure.
+func CheckImagePullSecrets(info *ProvisioningInfo) error {
+	for _, name := range info.ProvConfig.Spec.ImagePullSecrets {
+		_, err := info.Client.CoreV1().Secrets(info.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("image pull secret %s not found in namespace %s", name, info.Namespace)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencedSecrets returns the sorted names of the Secrets a pod template
+// mounts or reads into the environment of its containers.
+func referencedSecrets(template *corev1.PodTemplateSpec) []string {
+	names := sets.NewString()
+	for _, volume := range template.Spec.Volumes {
+		if volume.Secret != nil {
+			names.Insert(volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					names.Insert(source.Secret.Name)
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, template.Spec.InitContainers...), template.Spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names.Insert(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				names.Insert(envFrom.SecretRef.Name)
+			}
+		}
+	}
+	return names.List()
+}
+
+// secretChecksum hashes the content of a Secret. Unlike the resourceVersion,
+// it does not change on metadata-only updates.
+func secretChecksum(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s:%d:", key, len(secret.Data[key]))
+		hash.Write(secret.Data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// withSecretChecksums stamps the pod template with a checksum/<secret>
+// annotation for each Secret it references, so that rotating one of them
+// rolls out pods which would otherwise keep the content read on start.
+// Missing Secrets, and those whose name does not fit in an annotation key,
+// are skipped.
+func withSecretChecksums(info *ProvisioningInfo, template *corev1.PodTemplateSpec) error {
+	annotations := map[string]string{}
+	for key, value := range template.Annotations {
+		annotations[key] = value
+	}
+	for _, name := range referencedSecrets(template) {
+		key := secretChecksumAnnotationPrefix + name
+		if len(validation.IsQualifiedName(key)) > 0 {
+			continue
+		}
+		secret, err := info.Client.CoreV1().Secrets(info.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unable to get secret %s: %w", name, err)
+		}
+		annotations[key] = secretChecksum(secret)
+	}
+	template.Annotations = annotations
+	return nil
+}
+
 // createOrUpdateTlsSecret creates a Secret for the Ironic and Inspector TLS.
 // It updates the secret if the existing certificate is close to expiration.
 func createOrUpdateTlsSecret(info *ProvisioningInfo) error {
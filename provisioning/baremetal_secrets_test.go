@@ -225,6 +225,152 @@ func TestCreateAndUpdateTlsSecret(t *testing.T) {
 	}
 }
 
+func TestCheckExternalTlsSecret(t *testing.T) {
+	cases := []struct {
+		name          string
+		secret        *corev1.Secret
+		expectedError string
+	}{
+		{
+			name: "valid",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ironic-cert", Namespace: testNamespace},
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       []byte("cert"),
+					corev1.TLSPrivateKeyKey: []byte("key"),
+				},
+			},
+		},
+		{
+			name: "missing-key",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ironic-cert", Namespace: testNamespace},
+				Data: map[string][]byte{
+					corev1.TLSCertKey: []byte("cert"),
+				},
+			},
+			expectedError: "secret ironic-cert has no tls.key key",
+		},
+		{
+			name:          "missing-secret",
+			expectedError: "not found",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeClient := fakekube.NewSimpleClientset()
+			if tc.secret != nil {
+				kubeClient = fakekube.NewSimpleClientset(tc.secret)
+			}
+			info := &ProvisioningInfo{
+				Client:    kubeClient,
+				Namespace: testNamespace,
+				ProvConfig: &metal3iov1alpha1.Provisioning{
+					Spec: metal3iov1alpha1.ProvisioningSpec{ExternalTLSSecret: "ironic-cert"},
+				},
+			}
+
+			err := checkExternalTlsSecret(info)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+			// The operator never generates its own certificate
+			_, err = kubeClient.CoreV1().Secrets(testNamespace).Get(context.Background(), tlsSecretName, metav1.GetOptions{})
+			assert.True(t, apierrors.IsNotFound(err))
+		})
+	}
+}
+
+func TestCheckImagePullSecrets(t *testing.T) {
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-a", Namespace: testNamespace},
+	}
+	cases := []struct {
+		name          string
+		secrets       []string
+		expectedError string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:    "present",
+			secrets: []string{"registry-a"},
+		},
+		{
+			name:          "missing",
+			secrets:       []string{"registry-a", "registry-b"},
+			expectedError: "image pull secret registry-b not found in namespace " + testNamespace,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Client:    fakekube.NewSimpleClientset(existing),
+				Namespace: testNamespace,
+				ProvConfig: &metal3iov1alpha1.Provisioning{
+					Spec: metal3iov1alpha1.ProvisioningSpec{ImagePullSecrets: tc.secrets},
+				},
+			}
+
+			err := CheckImagePullSecrets(info)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestMetal3DeploymentSecretChecksums(t *testing.T) {
+	secret := func(name, key, value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			Data:       map[string][]byte{key: []byte(value)},
+		}
+	}
+	// The inspector secret is missing
+	kubeClient := fakekube.NewSimpleClientset(
+		secret(ironicSecretName, ironicPasswordKey, "password"),
+		secret(tlsSecretName, corev1.TLSCertKey, "cert"),
+	)
+	info := &ProvisioningInfo{
+		Client:     kubeClient,
+		Images:     &Images{},
+		Namespace:  testNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+	}
+	checksums := func() map[string]string {
+		deployment, err := newMetal3Deployment(info)
+		assert.NoError(t, err)
+		return deployment.Spec.Template.Annotations
+	}
+
+	initial := checksums()
+	assert.NotEmpty(t, initial["checksum/"+ironicSecretName])
+	assert.NotEmpty(t, initial["checksum/"+tlsSecretName])
+	assert.NotContains(t, initial, "checksum/"+inspectorSecretName)
+	assert.NotContains(t, podTemplateAnnotations, "checksum/"+ironicSecretName)
+
+	// Metadata-only changes do not roll out the pods
+	ironic := secret(ironicSecretName, ironicPasswordKey, "password")
+	ironic.Labels = map[string]string{"rotated": "false"}
+	_, err := kubeClient.CoreV1().Secrets(testNamespace).Update(context.Background(), ironic, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, initial, checksums())
+
+	_, err = kubeClient.CoreV1().Secrets(testNamespace).Update(context.Background(), secret(ironicSecretName, ironicPasswordKey, "rotated"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	_, err = kubeClient.CoreV1().Secrets(testNamespace).Update(context.Background(), secret(tlsSecretName, corev1.TLSCertKey, "renewed"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	rotated := checksums()
+	assert.NotEqual(t, initial["checksum/"+ironicSecretName], rotated["checksum/"+ironicSecretName])
+	assert.NotEqual(t, initial["checksum/"+tlsSecretName], rotated["checksum/"+tlsSecretName])
+}
+
 func TestRegistryPullSecret(t *testing.T) {
 	baremetalCR := &metal3iov1alpha1.Provisioning{
 		TypeMeta: metav1.TypeMeta{
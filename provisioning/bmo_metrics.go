@@ -0,0 +1,236 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+const (
+	bmoMetricsServiceName       = "metal3-baremetal-operator-metrics"
+	bmoMetricsPortName          = "metrics"
+	bmoMetricsPort              = 60000
+	bmoMetricsUpstreamPort      = 8085
+	bmoMetricsTLSSecretName     = "metal3-baremetal-operator-metrics-tls"
+	bmoMetricsTLSVolume         = "metrics-tls"
+	bmoMetricsTLSMountPath      = "/etc/tls/private"
+	kubeRBACProxyContainerName  = "kube-rbac-proxy"
+	kubeRBACProxyConfigMapName  = "baremetal-kube-rbac-proxy"
+	kubeRBACProxyConfigVolume   = "kube-rbac-proxy-config"
+	kubeRBACProxyConfigPath     = "/etc/baremetal-kube-rbac-proxy"
+	monitoringGroupVersion      = "monitoring.coreos.com/v1"
+	serviceMonitorKind          = "ServiceMonitor"
+	serviceMonitorResource      = "servicemonitors"
+	serviceCAFile               = "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt"
+	serviceAccountTokenFile     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	servingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+)
+
+// bmoMetricsVolumes are the serving certificate of the metrics service and
+// the authorization config shared with the cluster-baremetal-operator's own
+// kube-rbac-proxy, which allows Prometheus to read namespace/metrics.
+var bmoMetricsVolumes = []corev1.Volume{
+	{
+		Name: bmoMetricsTLSVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: bmoMetricsTLSSecretName,
+			},
+		},
+	},
+	{
+		Name: kubeRBACProxyConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: kubeRBACProxyConfigMapName,
+				},
+			},
+		},
+	},
+}
+
+// createContainerBMOKubeRBACProxy serves the baremetal-operator metrics,
+// which are only bound to the loopback, over TLS with the service-ca
+// certificate the ServiceMonitor verifies, authorizing the scraper's token.
+func createContainerBMOKubeRBACProxy(info *ProvisioningInfo) corev1.Container {
+	return corev1.Container{
+		Name:  kubeRBACProxyContainerName,
+		Image: info.Images.KubeRBACProxy,
+		Args: []string{
+			fmt.Sprintf("--secure-listen-address=0.0.0.0:%d", bmoMetricsPort),
+			fmt.Sprintf("--upstream=http://127.0.0.1:%d/", bmoMetricsUpstreamPort),
+			"--tls-cert-file=" + bmoMetricsTLSMountPath + "/" + corev1.TLSCertKey,
+			"--tls-private-key-file=" + bmoMetricsTLSMountPath + "/" + corev1.TLSPrivateKeyKey,
+			"--tls-cipher-suites=TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305",
+			"--config-file=" + kubeRBACProxyConfigPath + "/config-file.yaml",
+			"--logtostderr=true",
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          bmoMetricsPortName,
+				ContainerPort: bmoMetricsPort,
+			},
+		},
+		ImagePullPolicy: "IfNotPresent",
+		SecurityContext: withoutCapabilities(&info.ProvConfig.Spec),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      bmoMetricsTLSVolume,
+				ReadOnly:  true,
+				MountPath: bmoMetricsTLSMountPath,
+			},
+			{
+				Name:      kubeRBACProxyConfigVolume,
+				ReadOnly:  true,
+				MountPath: kubeRBACProxyConfigPath,
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
+
+func newBMOMetricsService(info *ProvisioningInfo) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bmoMetricsServiceName,
+			Namespace: info.Namespace,
+			Labels: map[string]string{
+				cboLabelName: bmoServiceName,
+			},
+			Annotations: map[string]string{
+				servingCertSecretAnnotation: bmoMetricsTLSSecretName,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				cboLabelName: bmoServiceName,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       bmoMetricsPortName,
+					Port:       bmoMetricsPort,
+					TargetPort: intstr.FromString(bmoMetricsPortName),
+				},
+			},
+		},
+	}
+}
+
+func newBMOServiceMonitor(info *ProvisioningInfo) *unstructured.Unstructured {
+	serviceMonitor := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"endpoints": []interface{}{
+					map[string]interface{}{
+						"port":            bmoMetricsPortName,
+						"scheme":          "https",
+						"bearerTokenFile": serviceAccountTokenFile,
+						"tlsConfig": map[string]interface{}{
+							"caFile":     serviceCAFile,
+							"serverName": fmt.Sprintf("%s.%s.svc", bmoMetricsServiceName, info.Namespace),
+						},
+					},
+				},
+				"namespaceSelector": map[string]interface{}{
+					"matchNames": []interface{}{info.Namespace},
+				},
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{
+						cboLabelName: bmoServiceName,
+					},
+				},
+			},
+		},
+	}
+	serviceMonitor.SetAPIVersion(monitoringGroupVersion)
+	serviceMonitor.SetKind(serviceMonitorKind)
+	serviceMonitor.SetName(bmoServiceName)
+	serviceMonitor.SetNamespace(info.Namespace)
+	return serviceMonitor
+}
+
+// apiResourceAvailable reports whether the resource of the given group version
+// is served, e.g. whether the ServiceMonitor CRD is installed, which is not the
+// case when the monitoring capability is disabled.
+func apiResourceAvailable(client discovery.DiscoveryInterface, groupVersion, name string) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func EnsureBaremetalOperatorMetrics(info *ProvisioningInfo) (updated bool, err error) {
+	metricsService := newBMOMetricsService(info)
+
+	err = controllerutil.SetControllerReference(info.ProvConfig, metricsService, info.Scheme)
+	if err != nil {
+		err = fmt.Errorf("unable to set controllerReference on service: %w", err)
+		return
+	}
+
+	_, updated, err = resourceapply.ApplyService(context.Background(),
+		info.Client.CoreV1(), info.EventRecorder, metricsService)
+	if err != nil {
+		err = fmt.Errorf("unable to apply baremetal-operator metrics service: %w", err)
+		return
+	}
+
+	available, err := apiResourceAvailable(info.Client.Discovery(), monitoringGroupVersion, serviceMonitorResource)
+	if err != nil {
+		err = fmt.Errorf("unable to discover %s resources: %w", monitoringGroupVersion, err)
+		return
+	}
+	if !available {
+		klog.Info("ServiceMonitor CRD not present, skipping baremetal-operator ServiceMonitor")
+		return
+	}
+
+	serviceMonitor := newBMOServiceMonitor(info)
+	err = controllerutil.SetControllerReference(info.ProvConfig, serviceMonitor, info.Scheme)
+	if err != nil {
+		err = fmt.Errorf("unable to set controllerReference on service monitor: %w", err)
+		return
+	}
+
+	_, monitorUpdated, err := resourceapply.ApplyServiceMonitor(context.Background(),
+		info.DynamicClient, info.EventRecorder, serviceMonitor)
+	if err != nil {
+		err = fmt.Errorf("unable to apply baremetal-operator service monitor: %w", err)
+	}
+	updated = updated || monitorUpdated
+	return
+}
+
+func DeleteBaremetalOperatorMetrics(info *ProvisioningInfo) error {
+	// The ServiceMonitor is garbage collected through its owner reference.
+	return client.IgnoreNotFound(info.Client.CoreV1().Services(info.Namespace).Delete(context.Background(), bmoMetricsServiceName, metav1.DeleteOptions{}))
+}
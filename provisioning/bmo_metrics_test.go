@@ -0,0 +1,120 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestNewBMOServiceMonitor(t *testing.T) {
+	info := &ProvisioningInfo{Namespace: testNamespace}
+	serviceMonitor := newBMOServiceMonitor(info)
+
+	assert.Equal(t, "monitoring.coreos.com/v1", serviceMonitor.GetAPIVersion())
+	assert.Equal(t, "ServiceMonitor", serviceMonitor.GetKind())
+
+	endpoints, found, err := unstructured.NestedSlice(serviceMonitor.Object, "spec", "endpoints")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Len(t, endpoints, 1)
+	endpoint := endpoints[0].(map[string]interface{})
+	assert.Equal(t, "metrics", endpoint["port"])
+	assert.Equal(t, "https", endpoint["scheme"])
+	assert.Equal(t, serviceAccountTokenFile, endpoint["bearerTokenFile"])
+	assert.Equal(t, map[string]interface{}{
+		"caFile":     serviceCAFile,
+		"serverName": "metal3-baremetal-operator-metrics." + testNamespace + ".svc",
+	}, endpoint["tlsConfig"])
+
+	service := newBMOMetricsService(info)
+	assert.Equal(t, bmoMetricsTLSSecretName, service.Annotations[servingCertSecretAnnotation])
+	assert.Equal(t, int32(60000), service.Spec.Ports[0].Port)
+}
+
+func TestNewBMOPodTemplateSpecMetricsProxy(t *testing.T) {
+	info := &ProvisioningInfo{
+		Namespace:    testNamespace,
+		Images:       &Images{BaremetalOperator: expectedBaremetalOperator, KubeRBACProxy: expectedKubeRBACProxy},
+		ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+		NetworkStack: NetworkStackV4,
+		Client:       fakekube.NewSimpleClientset(),
+		OSClient:     fakeconfigclientset.NewSimpleClientset(),
+	}
+	template, err := newBMOPodTemplateSpec(info, &map[string]string{})
+	assert.NoError(t, err)
+
+	var proxy *corev1.Container
+	for i, container := range template.Spec.Containers {
+		switch container.Name {
+		case "metal3-baremetal-operator":
+			// The plain HTTP metrics are not reachable from outside the pod
+			assert.Contains(t, container.Args, "127.0.0.1:8085")
+			for _, port := range container.Ports {
+				assert.NotEqual(t, bmoMetricsPortName, port.Name)
+			}
+		case kubeRBACProxyContainerName:
+			proxy = &template.Spec.Containers[i]
+		}
+	}
+	if !assert.NotNil(t, proxy) {
+		return
+	}
+	assert.Equal(t, expectedKubeRBACProxy, proxy.Image)
+	assert.Equal(t, []corev1.ContainerPort{{Name: bmoMetricsPortName, ContainerPort: bmoMetricsPort}}, proxy.Ports)
+	assert.Contains(t, proxy.Args, "--upstream=http://127.0.0.1:8085/")
+	assert.Contains(t, proxy.Args, "--tls-cert-file=/etc/tls/private/tls.crt")
+	assert.Contains(t, proxy.VolumeMounts, corev1.VolumeMount{Name: bmoMetricsTLSVolume, ReadOnly: true, MountPath: "/etc/tls/private"})
+
+	// The serving secret requested by the metrics service is mounted
+	assert.Contains(t, template.Spec.Volumes, corev1.Volume{
+		Name: bmoMetricsTLSVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: bmoMetricsTLSSecretName},
+		},
+	})
+}
+
+func TestEnsureBaremetalOperatorMetricsWithoutMonitoring(t *testing.T) {
+	kubeClient := fakekube.NewSimpleClientset()
+	// No monitoring.coreos.com resources are registered with discovery
+	kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{}
+
+	info := &ProvisioningInfo{
+		Client:    kubeClient,
+		Namespace: testNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+		},
+		Scheme:        scheme,
+		EventRecorder: events.NewLoggingEventRecorder("tests"),
+	}
+
+	updated, err := EnsureBaremetalOperatorMetrics(info)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	service, err := kubeClient.CoreV1().Services(testNamespace).Get(context.Background(), bmoMetricsServiceName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "metrics", service.Spec.Ports[0].Name)
+	// The target port is the name of the metrics container port
+	assert.Equal(t, intstr.FromString(bmoMetricsPortName), service.Spec.Ports[0].TargetPort)
+	assert.Equal(t, map[string]string{cboLabelName: bmoServiceName}, service.Spec.Selector)
+	if assert.Len(t, service.OwnerReferences, 1) {
+		assert.Equal(t, metal3iov1alpha1.ProvisioningSingletonName, service.OwnerReferences[0].Name)
+		assert.True(t, *service.OwnerReferences[0].Controller)
+	}
+
+	assert.NoError(t, DeleteBaremetalOperatorMetrics(info))
+	assert.NoError(t, DeleteBaremetalOperatorMetrics(info))
+}
@@ -23,6 +23,8 @@ import (
 const (
 	bmoServiceName    = "metal3-baremetal-operator"
 	bmoDeploymentName = "metal3-baremetal-operator"
+	bmoDebugPortName  = "debug"
+	bmoDebugPort      = 6060
 	// Default cert directory set by kubebuilder
 	baremetalWebhookCertMountPath = "/tmp/k8s-webhook-server/serving-certs"
 	baremetalWebhookCertVolume    = "cert"
@@ -110,9 +112,11 @@ func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container,
 				ContainerPort: int32(webhookPort),
 			},
 		},
-		Command:         []string{"/baremetal-operator"},
-		Args:            []string{"--health-addr", ":9446", "-build-preprov-image"},
+		Command: []string{"/baremetal-operator"},
+		// Metrics are only served through the kube-rbac-proxy sidecar
+		Args:            []string{"--health-addr", ":9446", "--metrics-addr", fmt.Sprintf("127.0.0.1:%d", bmoMetricsUpstreamPort), "-build-preprov-image"},
 		ImagePullPolicy: "IfNotPresent",
+		SecurityContext: withoutCapabilities(&info.ProvConfig.Spec),
 		VolumeMounts: []corev1.VolumeMount{
 			ironicCredentialsMount,
 			inspectorCredentialsMount,
@@ -147,7 +151,7 @@ func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container,
 			},
 			{
 				Name:  ironicInsecureEnvVar,
-				Value: "true",
+				Value: strconv.FormatBool(!info.ProvConfig.Spec.InternalTLS),
 			},
 			buildEnvVar(deployKernelUrl, &info.ProvConfig.Spec),
 			{
@@ -177,6 +181,8 @@ func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container,
 		},
 	}
 
+	container.Env = append(container.Env, info.ProvConfig.Spec.ExtraBaremetalOperatorEnv...)
+
 	if !info.BaremetalWebhookEnabled {
 		// Webhook dependencies are not ready, thus we disable webhook explicitly,
 		// since default is enabled.
@@ -185,6 +191,15 @@ func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container,
 		container.Args = append(container.Args, "--webhook-port", baremetalWebhookPort)
 	}
 
+	if info.ProvConfig.Spec.EnableDebugEndpoints {
+		// Only reachable through port-forwarding or from within the pod
+		container.Args = append(container.Args, "--dev", "--pprof-addr", fmt.Sprintf("127.0.0.1:%d", bmoDebugPort))
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			Name:          bmoDebugPortName,
+			ContainerPort: bmoDebugPort,
+		})
+	}
+
 	return container, nil
 }
 
@@ -217,7 +232,10 @@ func newBMOPodTemplateSpec(info *ProvisioningInfo, labels *map[string]string) (*
 		},
 	}
 
-	containers := injectProxyAndCA([]corev1.Container{container}, info.Proxy)
+	containers := withResourceRequests(injectProxyAndCA([]corev1.Container{container}, info.Proxy, &info.ProvConfig.Spec), &info.ProvConfig.Spec)
+	containers = withImageOverrides(containers, &info.ProvConfig.Spec)
+	containers = append(containers, createContainerBMOKubeRBACProxy(info))
+	volumes := append(withTLSSecret(withTrustedCAVolume(bmoVolumes, &info.ProvConfig.Spec), &info.ProvConfig.Spec), bmoMetricsVolumes...)
 
 	return &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -225,7 +243,7 @@ func newBMOPodTemplateSpec(info *ProvisioningInfo, labels *map[string]string) (*
 			Labels:      *labels,
 		},
 		Spec: corev1.PodSpec{
-			Volumes:            bmoVolumes,
+			Volumes:            volumes,
 			Containers:         containers,
 			HostNetwork:        false,
 			DNSPolicy:          corev1.DNSClusterFirstWithHostNet,
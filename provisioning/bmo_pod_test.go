@@ -50,6 +50,9 @@ func TestNewBMOContainers(t *testing.T) {
 				{Name: "IRONIC_EXTERNAL_URL_V6", Value: ""},
 			},
 		},
+		"kube-rbac-proxy": {
+			Name: "kube-rbac-proxy",
+		},
 	}
 	withEnv := func(c corev1.Container, ne ...corev1.EnvVar) corev1.Container {
 		newMap := map[string]corev1.EnvVar{}
@@ -89,6 +92,7 @@ func TestNewBMOContainers(t *testing.T) {
 				withEnv(
 					containers["metal3-baremetal-operator"],
 				),
+				containers["kube-rbac-proxy"],
 			},
 			sshkey: "sshkey",
 		},
@@ -100,6 +104,7 @@ func TestNewBMOContainers(t *testing.T) {
 					containers["metal3-baremetal-operator"],
 					envWithValue("IRONIC_EXTERNAL_URL_V6", fmt.Sprintf("https://[%s]:6183", testProvisioningIPv6)),
 				),
+				containers["kube-rbac-proxy"],
 			},
 			sshkey: "sshkey",
 		},
@@ -115,6 +120,7 @@ func TestNewBMOContainers(t *testing.T) {
 					envWithValue("IRONIC_ENDPOINT", "https://metal3-state.openshift-machine-api.svc.cluster.local:6388/v1/"),
 					envWithValue("IRONIC_INSPECTOR_ENDPOINT", "https://metal3-state.openshift-machine-api.svc.cluster.local:5051/v1/"),
 				),
+				containers["kube-rbac-proxy"],
 			},
 			sshkey: "sshkey",
 		},
@@ -130,6 +136,7 @@ func TestNewBMOContainers(t *testing.T) {
 					envWithValue("IRONIC_ENDPOINT", "https://metal3-state.openshift-machine-api.svc.cluster.local:6388/v1/"),
 					envWithValue("IRONIC_INSPECTOR_ENDPOINT", "https://metal3-state.openshift-machine-api.svc.cluster.local:5051/v1/"),
 				),
+				containers["kube-rbac-proxy"],
 			},
 			sshkey: "",
 		},
@@ -194,3 +201,100 @@ func TestNewBMOContainers(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBMOContainerDebugEndpoints(t *testing.T) {
+	tCases := []struct {
+		name          string
+		config        *metal3iov1alpha1.ProvisioningSpec
+		expectedArgs  []string
+		expectedPorts []corev1.ContainerPort
+	}{
+		{
+			name:         "Disabled",
+			config:       managedProvisioning().build(),
+			expectedArgs: []string{"--health-addr", ":9446", "--metrics-addr", "127.0.0.1:8085", "-build-preprov-image", "--webhook-port", "0"},
+			expectedPorts: []corev1.ContainerPort{
+				{Name: "webhook-server", HostPort: 9447, ContainerPort: 9447},
+			},
+		},
+		{
+			name:         "Enabled",
+			config:       managedProvisioning().EnableDebugEndpoints(true).build(),
+			expectedArgs: []string{"--health-addr", ":9446", "--metrics-addr", "127.0.0.1:8085", "-build-preprov-image", "--webhook-port", "0", "--dev", "--pprof-addr", "127.0.0.1:6060"},
+			expectedPorts: []corev1.ContainerPort{
+				{Name: "webhook-server", HostPort: 9447, ContainerPort: 9447},
+				{Name: "debug", ContainerPort: 6060},
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Namespace:    "openshift-machine-api",
+				Images:       &Images{BaremetalOperator: expectedBaremetalOperator},
+				ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+				NetworkStack: NetworkStackV4,
+				Client:       fakekube.NewSimpleClientset(),
+				OSClient:     fakeconfigclientset.NewSimpleClientset(),
+			}
+			container, err := createContainerBaremetalOperator(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedArgs, container.Args)
+			assert.Equal(t, tc.expectedPorts, container.Ports)
+		})
+	}
+}
+
+func TestNewBMOContainerExtraEnv(t *testing.T) {
+	info := &ProvisioningInfo{
+		Namespace:    "openshift-machine-api",
+		Images:       &Images{BaremetalOperator: expectedBaremetalOperator},
+		ProvConfig:   &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().ExtraBaremetalOperatorEnv(corev1.EnvVar{Name: "BMO_CONCURRENCY", Value: "10"}).build()},
+		NetworkStack: NetworkStackV4,
+		Client:       fakekube.NewSimpleClientset(),
+		OSClient:     fakeconfigclientset.NewSimpleClientset(),
+	}
+	container, err := createContainerBaremetalOperator(info)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.EnvVar{Name: "BMO_CONCURRENCY", Value: "10"}, container.Env[len(container.Env)-1])
+}
+
+func TestGetWatchNamespace(t *testing.T) {
+	ownNamespace := corev1.EnvVar{
+		Name: "WATCH_NAMESPACE",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+		},
+	}
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected corev1.EnvVar
+	}{
+		{
+			name:     "single",
+			config:   managedProvisioning().build(),
+			expected: ownNamespace,
+		},
+		{
+			name:     "all",
+			config:   managedProvisioning().WatchAllNamespaces(true).build(),
+			expected: corev1.EnvVar{Name: "WATCH_NAMESPACE", Value: ""},
+		},
+		{
+			name:     "list",
+			config:   managedProvisioning().WatchNamespaces("openshift-machine-api", "edge-hosts").build(),
+			expected: corev1.EnvVar{Name: "WATCH_NAMESPACE", Value: "openshift-machine-api,edge-hosts"},
+		},
+		{
+			name:     "list takes precedence over all",
+			config:   managedProvisioning().WatchAllNamespaces(true).WatchNamespaces("edge-hosts").build(),
+			expected: corev1.EnvVar{Name: "WATCH_NAMESPACE", Value: "edge-hosts"},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getWatchNamespace(tc.config))
+		})
+	}
+}
@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 )
 
+const imageDigestSeparator = "@sha256:"
+
 type Images struct {
 	BaremetalOperator            string `json:"baremetalOperator"`
 	Ironic                       string `json:"baremetalIronic"`
@@ -30,6 +33,8 @@ type Images struct {
 	IronicAgent                  string `json:"baremetalIronicAgent"`
 	ImageCustomizationController string `json:"imageCustomizationController"`
 	MachineOSImages              string `json:"machineOSImages"`
+	Keepalived                   string `json:"keepalived"`
+	KubeRBACProxy                string `json:"kubeRBACProxy"`
 }
 
 func GetContainerImages(containerImages *Images, imagesFilePath string) error {
@@ -43,3 +48,69 @@ func GetContainerImages(containerImages *Images, imagesFilePath string) error {
 	}
 	return nil
 }
+
+// WithRegistryMirror returns a copy of the images pulled from the given
+// registry instead, keeping their repository, tag and digest.
+func WithRegistryMirror(containerImages *Images, mirror string) *Images {
+	mirrored := *containerImages
+	for _, image := range []*string{
+		&mirrored.BaremetalOperator,
+		&mirrored.Ironic,
+		&mirrored.MachineOsDownloader,
+		&mirrored.StaticIpManager,
+		&mirrored.IronicAgent,
+		&mirrored.ImageCustomizationController,
+		&mirrored.MachineOSImages,
+		&mirrored.Keepalived,
+		&mirrored.KubeRBACProxy,
+	} {
+		if *image != "" {
+			*image = replaceRegistry(*image, mirror)
+		}
+	}
+	return &mirrored
+}
+
+// replaceRegistry swaps the registry of an image reference. As with
+// container runtimes, the first component of the reference is only a
+// registry when it contains a dot or a port, or is localhost.
+func replaceRegistry(image, registry string) string {
+	repository := image
+	if i := strings.Index(image, "/"); i >= 0 {
+		first := image[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			repository = image[i+1:]
+		}
+	}
+	return registry + "/" + repository
+}
+
+// ValidateImageDigests ensures every image reference is pinned by digest,
+// reporting the images.json keys of the ones that are not.
+func ValidateImageDigests(containerImages *Images) error {
+	references := []struct {
+		field string
+		image string
+	}{
+		{"baremetalOperator", containerImages.BaremetalOperator},
+		{"baremetalIronic", containerImages.Ironic},
+		{"baremetalMachineOsDownloader", containerImages.MachineOsDownloader},
+		{"baremetalStaticIpManager", containerImages.StaticIpManager},
+		{"baremetalIronicAgent", containerImages.IronicAgent},
+		{"imageCustomizationController", containerImages.ImageCustomizationController},
+		{"machineOSImages", containerImages.MachineOSImages},
+		{"keepalived", containerImages.Keepalived},
+		{"kubeRBACProxy", containerImages.KubeRBACProxy},
+	}
+
+	var unpinned []string
+	for _, reference := range references {
+		if !strings.Contains(reference.image, imageDigestSeparator) {
+			unpinned = append(unpinned, reference.field)
+		}
+	}
+	if len(unpinned) > 0 {
+		return fmt.Errorf("images not pinned by digest: %s", strings.Join(unpinned, ", "))
+	}
+	return nil
+}
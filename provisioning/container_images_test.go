@@ -13,6 +13,8 @@ var (
 	expectedIronicAgent                  = "registry.ci.openshift.org/openshift:ironic-agent"
 	expectedImageCustomizationController = "registry.ci.openshift.org/openshift:machine-image-customization-controller"
 	expectedMachineOSImages              = "registry.ci.openshift.org/openshift:machine-os-images"
+	expectedKeepalived                   = "registry.ci.openshift.org/openshift:keepalived-ipfailover"
+	expectedKubeRBACProxy                = "registry.ci.openshift.org/openshift:kube-rbac-proxy"
 )
 
 func TestGetContainerImages(t *testing.T) {
@@ -50,10 +52,87 @@ func TestGetContainerImages(t *testing.T) {
 					containerImages.StaticIpManager != expectedIronicStaticIpManager ||
 					containerImages.IronicAgent != expectedIronicAgent ||
 					containerImages.ImageCustomizationController != expectedImageCustomizationController ||
-					containerImages.MachineOSImages != expectedMachineOSImages {
+					containerImages.MachineOSImages != expectedMachineOSImages ||
+					containerImages.Keepalived != expectedKeepalived ||
+					containerImages.KubeRBACProxy != expectedKubeRBACProxy {
 					t.Errorf("failed GetContainerImages. One or more Baremetal container images do not match the expected images.")
 				}
 			}
 		})
 	}
 }
+
+func TestValidateImageDigests(t *testing.T) {
+	digest := "@sha256:4c8b4d1d42b6a0fc1cfc8a2c5c6ec1d0e3c7f0bc6a8e1e3fe4d0b2f6b1f9e2a7"
+	pinned := Images{
+		BaremetalOperator:            "quay.io/openshift/baremetal-operator" + digest,
+		Ironic:                       "quay.io/openshift/ironic" + digest,
+		MachineOsDownloader:          "quay.io/openshift/ironic-machine-os-downloader" + digest,
+		StaticIpManager:              "quay.io/openshift/ironic-static-ip-manager" + digest,
+		IronicAgent:                  "quay.io/openshift/ironic-agent" + digest,
+		ImageCustomizationController: "quay.io/openshift/machine-image-customization-controller" + digest,
+		MachineOSImages:              "quay.io/openshift/machine-os-images" + digest,
+		Keepalived:                   "quay.io/openshift/keepalived-ipfailover" + digest,
+		KubeRBACProxy:                "quay.io/openshift/kube-rbac-proxy" + digest,
+	}
+	mixed := pinned
+	mixed.Ironic = expectedIronic
+
+	testCases := []struct {
+		name        string
+		images      Images
+		expectedErr string
+	}{
+		{
+			name:   "all pinned",
+			images: pinned,
+		},
+		{
+			name:        "ironic tagged",
+			images:      mixed,
+			expectedErr: "images not pinned by digest: baremetalIronic",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateImageDigests(&tc.images)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Errorf("expected error %q, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestWithRegistryMirror(t *testing.T) {
+	digest := "@sha256:4c8b4d1d42b6a0fc1cfc8a2c5c6ec1d0e3c7f0bc6a8e1e3fe4d0b2f6b1f9e2a7"
+	images := Images{
+		BaremetalOperator:            "quay.io/openshift/baremetal-operator" + digest,
+		Ironic:                       "registry.ci.openshift.org:443/openshift/ironic:latest",
+		MachineOsDownloader:          "localhost/ironic-machine-os-downloader:latest",
+		StaticIpManager:              "openshift/ironic-static-ip-manager:4.14",
+		IronicAgent:                  "ironic-agent" + digest,
+		ImageCustomizationController: "[fd00::1]:5000/openshift/machine-image-customization-controller" + digest,
+	}
+
+	expected := Images{
+		BaremetalOperator:            "mirror.example.com:5000/openshift/baremetal-operator" + digest,
+		Ironic:                       "mirror.example.com:5000/openshift/ironic:latest",
+		MachineOsDownloader:          "mirror.example.com:5000/ironic-machine-os-downloader:latest",
+		StaticIpManager:              "mirror.example.com:5000/openshift/ironic-static-ip-manager:4.14",
+		IronicAgent:                  "mirror.example.com:5000/ironic-agent" + digest,
+		ImageCustomizationController: "mirror.example.com:5000/openshift/machine-image-customization-controller" + digest,
+	}
+	if mirrored := WithRegistryMirror(&images, "mirror.example.com:5000"); *mirrored != expected {
+		t.Errorf("expected %+v, got %+v", expected, *mirrored)
+	}
+	// The original images are left untouched
+	if images.BaremetalOperator != "quay.io/openshift/baremetal-operator"+digest {
+		t.Errorf("original images modified: %+v", images)
+	}
+}
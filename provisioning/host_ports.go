@@ -0,0 +1,97 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// metal3HostPorts returns the host ports bound by the metal3 pod
+func metal3HostPorts(template *corev1.PodTemplateSpec) []int32 {
+	ports := []int32{}
+	for _, container := range template.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				ports = append(ports, port.HostPort)
+			}
+		}
+	}
+	return ports
+}
+
+// isMetal3Pod reports whether the pod belongs to the metal3 deployment, whose
+// host ports are released when it is replaced.
+func isMetal3Pod(info *ProvisioningInfo, pod *corev1.Pod) bool {
+	return pod.Namespace == info.Namespace && pod.Labels[cboLabelName] == stateService
+}
+
+// CheckMetal3HostPorts is a read-only pre-flight check of the host ports the
+// metal3 pod needs on the nodes it can be scheduled on. It returns a message
+// naming the conflicting ports and nodes when other pods leave fewer free
+// nodes than metal3 replicas, in which case the metal3 pods would stay
+// pending, and an empty message otherwise.
+func CheckMetal3HostPorts(info *ProvisioningInfo) (string, error) {
+	ctx := context.Background()
+
+	// A paused metal3 deployment runs no pods
+	if info.ProvConfig.Spec.Paused {
+		return "", nil
+	}
+
+	template := newMetal3PodTemplateSpec(info, &map[string]string{})
+	ports := metal3HostPorts(template)
+	if len(ports) == 0 {
+		return "", nil
+	}
+
+	nodes, err := info.Client.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(template.Spec.NodeSelector).String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to list nodes: %w", err)
+	}
+	candidates := map[string]bool{}
+	for _, node := range nodes.Items {
+		if !node.Spec.Unschedulable {
+			candidates[node.Name] = true
+		}
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	pods, err := info.Client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to list pods: %w", err)
+	}
+	conflictingNodes := map[string]bool{}
+	conflicts := []string{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !candidates[pod.Spec.NodeName] || isMetal3Pod(info, pod) ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				for _, needed := range ports {
+					if port.HostPort == needed {
+						conflictingNodes[pod.Spec.NodeName] = true
+						conflicts = append(conflicts, fmt.Sprintf("port %d on node %s (pod %s/%s)", needed, pod.Spec.NodeName, pod.Namespace, pod.Name))
+					}
+				}
+			}
+		}
+	}
+
+	if len(conflictingNodes) < len(candidates) {
+		return "", nil
+	}
+	sort.Strings(conflicts)
+	return fmt.Sprintf("host ports needed by the metal3 pod are in use: %s", strings.Join(conflicts, ", ")), nil
+}
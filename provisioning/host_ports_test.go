@@ -0,0 +1,109 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func TestCheckMetal3HostPorts(t *testing.T) {
+	namespace := "openshift-machine-api"
+	master := func(name string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"node-role.kubernetes.io/master": ""},
+		}}
+	}
+	podWithHostPort := func(namespace, name, node string, port int32, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Spec: corev1.PodSpec{
+				NodeName: node,
+				Containers: []corev1.Container{{
+					Name:  "main",
+					Ports: []corev1.ContainerPort{{ContainerPort: port, HostPort: port}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	// A worker is not a candidate for the metal3 pod
+	worker := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}}
+
+	tCases := []struct {
+		name            string
+		config          *metal3iov1alpha1.ProvisioningSpec
+		objects         []runtime.Object
+		expectedMessage string
+	}{
+		{
+			name:   "no conflict",
+			config: managedProvisioning().build(),
+			objects: []runtime.Object{
+				master("master-0"), worker,
+				podWithHostPort("other", "api", "worker-0", 6385, nil),
+			},
+		},
+		{
+			name:   "conflicting pod",
+			config: managedProvisioning().build(),
+			objects: []runtime.Object{
+				master("master-0"), worker,
+				podWithHostPort("other", "api", "master-0", 6385, nil),
+			},
+			expectedMessage: "host ports needed by the metal3 pod are in use: port 6385 on node master-0 (pod other/api)",
+		},
+		{
+			name:   "another master is free",
+			config: managedProvisioning().build(),
+			objects: []runtime.Object{
+				master("master-0"), master("master-1"),
+				podWithHostPort("other", "api", "master-0", 6385, nil),
+			},
+		},
+		{
+			name:   "current metal3 pod",
+			config: managedProvisioning().build(),
+			objects: []runtime.Object{
+				master("master-0"),
+				podWithHostPort(namespace, "metal3-abc", "master-0", 6385, map[string]string{cboLabelName: stateService}),
+			},
+		},
+		{
+			name:   "paused",
+			config: managedProvisioning().Paused(true).build(),
+			objects: []runtime.Object{
+				master("master-0"),
+				podWithHostPort("other", "api", "master-0", 6385, nil),
+			},
+		},
+		{
+			name:   "host ports disabled",
+			config: managedProvisioning().DisableHostPorts(true).build(),
+			objects: []runtime.Object{
+				master("master-0"),
+				podWithHostPort("other", "api", "master-0", 6385, nil),
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Client:     fakekube.NewSimpleClientset(tc.objects...),
+				Namespace:  namespace,
+				Images:     &Images{},
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+
+			message, err := CheckMetal3HostPorts(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMessage, message)
+		})
+	}
+}
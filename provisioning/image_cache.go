@@ -63,7 +63,7 @@ func imageVolume() corev1.Volume {
 
 // Helper to transform the first level (metal3 pod) cache URLs to second level
 // (control-plane daemonset) cache
-func transformURL(targetNamespace, URL string) (string, error) {
+func transformURL(targetNamespace, port, URL string) (string, error) {
 	downloadURL, err := url.Parse(URL)
 	if err != nil {
 		return "", err
@@ -81,7 +81,7 @@ func transformURL(targetNamespace, URL string) (string, error) {
 	cacheURL := url.URL{
 		Scheme: "http",
 		Host: net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", stateService, targetNamespace),
-			baremetalHttpPort),
+			port),
 		Path: fmt.Sprintf("/images/%s/%s", imageName, imageName),
 	}
 	return cacheURL.String(), nil
@@ -135,7 +135,7 @@ func createContainerImageCache(images *Images) corev1.Container {
 }
 
 func newImageCacheInitContainers(info *ProvisioningInfo) ([]corev1.Container, error) {
-	newURL, err := transformURL(info.Namespace, info.ProvConfig.Spec.ProvisioningOSDownloadURL)
+	newURL, err := transformURL(info.Namespace, getHttpPort(&info.ProvConfig.Spec), info.ProvConfig.Spec.ProvisioningOSDownloadURL)
 	if err != nil {
 		return nil, err
 	}
@@ -145,12 +145,12 @@ func newImageCacheInitContainers(info *ProvisioningInfo) ([]corev1.Container, er
 	}, nil
 }
 
-func newImageCacheContainers(images *Images, proxy *osconfigv1.Proxy) []corev1.Container {
+func newImageCacheContainers(images *Images, proxy *osconfigv1.Proxy, config *metal3iov1alpha1.ProvisioningSpec) []corev1.Container {
 	containers := []corev1.Container{
 		createContainerImageCache(images),
 	}
 
-	return injectProxyAndCA(containers, proxy)
+	return injectProxyAndCA(containers, proxy, config)
 }
 
 func newImageCachePodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSpec, error) {
@@ -158,7 +158,7 @@ func newImageCachePodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSp
 	if err != nil {
 		return nil, err
 	}
-	containers := newImageCacheContainers(info.Images, info.Proxy)
+	containers := withoutHostPorts(newImageCacheContainers(info.Images, info.Proxy, &info.ProvConfig.Spec), &info.ProvConfig.Spec)
 
 	tolerations := []corev1.Toleration{
 		{
@@ -197,9 +197,9 @@ func newImageCachePodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSp
 			},
 			Volumes: []corev1.Volume{
 				imageVolume(),
-				trustedCAVolume(),
+				newTrustedCAVolume(&info.ProvConfig.Spec),
 			},
-			InitContainers:    injectProxyAndCA(initContainers, info.Proxy),
+			InitContainers:    withDownloadProxy(injectProxyAndCA(initContainers, info.Proxy, &info.ProvConfig.Spec), &info.ProvConfig.Spec),
 			Containers:        containers,
 			HostNetwork:       true,
 			DNSPolicy:         corev1.DNSClusterFirstWithHostNet,
@@ -85,7 +85,7 @@ func getUrlFromIP(ipAddrs []string, port int) string {
 
 func createImageCustomizationContainer(images *Images, info *ProvisioningInfo, ironicIPs []string, inspectorIPs []string) corev1.Container {
 	noProxy := append(ironicIPs, inspectorIPs...)
-	envVars := envWithProxy(info.Proxy, []corev1.EnvVar{}, noProxy)
+	envVars := envWithProxy(info.Proxy, &info.ProvConfig.Spec, []corev1.EnvVar{}, noProxy)
 
 	container := corev1.Container{
 		Name:  "machine-image-customization-controller",
@@ -117,12 +117,12 @@ func createImageCustomizationContainer(images *Images, info *ProvisioningInfo, i
 			},
 			corev1.EnvVar{
 				Name:  ironicBaseUrl,
-				Value: getUrlFromIP(ironicIPs, baremetalIronicPort),
+				Value: getUrlFromIP(ironicIPs, getIronicPort(&info.ProvConfig.Spec)),
 			},
 			corev1.EnvVar{
 				Name: ironicInspectorBaseUrl,
 				// TODO(dtantsur): when inspector is gone, we may be able to stop passing this URL
-				Value: getUrlFromIP(inspectorIPs, baremetalIronicInspectorPort),
+				Value: getUrlFromIP(inspectorIPs, getInspectorPort(&info.ProvConfig.Spec)),
 			},
 			corev1.EnvVar{
 				Name:  ironicAgentImage,
@@ -159,10 +159,13 @@ func newImageCustomizationPodTemplateSpec(info *ProvisioningInfo, labels *map[st
 		createImageCustomizationContainer(info.Images, info, ironicIPs, inspectorIPs),
 	}
 
-	// Extract the pre-provisioning images from a container in the payload
+	// Extract the pre-provisioning images from a container in the payload,
+	// then make sure the deploy ISO and initrd customized by the controller
+	// are there
 	initContainers := []corev1.Container{
 		// TODO(dtantsur): use --image-build instead of --all once ICC has its own isolated volume
 		createInitContainerMachineOSImages(info, "--all", imageVolumeMount, imageSharedDir),
+		createInitContainerMachineOSImagesVerify(info, imageVolumeMount, deployISOFile, deployInitrdFile),
 	}
 
 	tolerations := []corev1.Toleration{
@@ -196,7 +199,7 @@ func newImageCustomizationPodTemplateSpec(info *ProvisioningInfo, labels *map[st
 		},
 		Spec: corev1.PodSpec{
 			Containers:         containers,
-			InitContainers:     injectProxyAndCA(initContainers, info.Proxy),
+			InitContainers:     injectProxyAndCA(initContainers, info.Proxy, &info.ProvConfig.Spec),
 			HostNetwork:        false,
 			DNSPolicy:          corev1.DNSClusterFirstWithHostNet,
 			PriorityClassName:  "system-node-critical",
@@ -206,7 +209,7 @@ func newImageCustomizationPodTemplateSpec(info *ProvisioningInfo, labels *map[st
 			Volumes: []corev1.Volume{
 				imageRegistriesVolume(),
 				imageVolume(),
-				trustedCAVolume(),
+				newTrustedCAVolume(&info.ProvConfig.Spec),
 			},
 		},
 	}
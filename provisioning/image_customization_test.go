@@ -23,6 +23,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "github.com/openshift/api/config/v1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
 
 func TestNewImageCustomizationContainer(t *testing.T) {
@@ -52,7 +53,7 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 		Env: []corev1.EnvVar{
 			{Name: "HTTP_PROXY", Value: "https://172.2.0.1:3128"},
 			{Name: "HTTPS_PROXY", Value: "https://172.2.0.1:3128"},
-			{Name: "NO_PROXY", Value: ".example.com,192.168.0.2,192.168.0.2"},
+			{Name: "NO_PROXY", Value: ".example.com,192.168.0.2"},
 			{Name: "DEPLOY_ISO", Value: "/shared/html/images/ironic-python-agent.iso"},
 			{Name: "DEPLOY_INITRD", Value: "/shared/html/images/ironic-python-agent.initramfs"},
 			{Name: "IRONIC_BASE_URL", Value: "https://192.168.0.2:6385"},
@@ -133,6 +134,7 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 				SSHKey:       "sshkey",
 				NetworkStack: NetworkStackV4,
 				Proxy:        tc.proxy,
+				ProvConfig:   &metal3iov1alpha1.Provisioning{},
 			}
 			actualContainer := createImageCustomizationContainer(&images, info, tc.ironicIPs, tc.inspectorIPs)
 			for e := range actualContainer.Env {
@@ -142,6 +144,30 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 	}
 }
 
+func TestNewImageCustomizationPodTemplateSpecInitContainers(t *testing.T) {
+	images := Images{
+		MachineOSImages:              "registry.ci.openshift.org/openshift:machine-os-images",
+		ImageCustomizationController: "registry.ci.openshift.org/openshift:image-customization-controller",
+	}
+	info := &ProvisioningInfo{
+		Images:       &images,
+		NetworkStack: NetworkStackV4,
+		ProvConfig:   &metal3iov1alpha1.Provisioning{},
+	}
+
+	template := newImageCustomizationPodTemplateSpec(info, &map[string]string{}, []string{"192.168.0.2"}, []string{"192.168.0.2"})
+	initContainers := template.Spec.InitContainers
+	assert.Len(t, initContainers, 2)
+	assert.Equal(t, "machine-os-images", initContainers[0].Name)
+
+	verify := initContainers[1]
+	assert.Equal(t, "machine-os-images-verify", verify.Name)
+	assert.Equal(t, images.MachineOSImages, verify.Image)
+	assert.Contains(t, verify.VolumeMounts, imageVolumeMount)
+	assert.Contains(t, verify.Command[2], "/shared/html/images/ironic-python-agent.iso /shared/html/images/ironic-python-agent.initramfs")
+	assert.Contains(t, verify.Command[2], `[ ! -s "$f" ]`)
+}
+
 func TestGetUrlFromIP(t *testing.T) {
 	tests := []struct {
 		ipAddr []string
@@ -159,6 +185,10 @@ func TestGetUrlFromIP(t *testing.T) {
 			ipAddr: []string{"2001:db8::1", "192.0.2.1"},
 			want:   "https://[2001:db8::1]:6385,https://192.0.2.1:6385",
 		},
+		{
+			ipAddr: []string{"metal3-state.openshift-machine-api.svc"},
+			want:   "https://metal3-state.openshift-machine-api.svc:6385",
+		},
 		{
 			ipAddr: nil,
 			want:   "",
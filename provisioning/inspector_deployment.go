@@ -0,0 +1,229 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+)
+
+const (
+	inspectorDeploymentName = "metal3-inspector"
+	inspectorAppName        = "metal3-inspector"
+)
+
+// UseSplitDeployment reports whether inspector runs in its own deployment
+// instead of the metal3 pod.
+func UseSplitDeployment(config *metal3iov1alpha1.ProvisioningSpec) bool {
+	return config.SplitDeployment
+}
+
+// withInspectorServingPort makes inspector serve its port directly, as httpd
+// no longer proxies it once it runs in its own pod.
+func withInspectorServingPort(container corev1.Container, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
+	inspectorPort := getInspectorPort(config)
+	if UseIronicProxy(config) {
+		inspectorPort = inspectorPrivatePort
+	}
+
+	env := []corev1.EnvVar{}
+	for _, envVar := range container.Env {
+		switch envVar.Name {
+		case inspectorProxyEnvVar:
+			envVar.Value = "false"
+		case inspectorPrivatePortEnvVar:
+			continue
+		}
+		env = append(env, envVar)
+	}
+	container.Env = append(env,
+		corev1.EnvVar{
+			Name:  inspectorListenPortEnvVar,
+			Value: fmt.Sprint(inspectorPort),
+		},
+		setIronicHtpasswdHash(inspectorHtpasswdEnvVar, inspectorSecretName),
+	)
+	container.VolumeMounts = append(container.VolumeMounts, inspectorCredentialsMount)
+	container.Ports = append(container.Ports, corev1.ContainerPort{
+		Name:          "inspector",
+		ContainerPort: int32(inspectorPort),
+		HostPort:      int32(inspectorPort),
+	})
+	return container
+}
+
+func newMetal3InspectorContainers(info *ProvisioningInfo) []corev1.Container {
+	_, containers := partitionMetal3Containers(newAllMetal3Containers(info), &info.ProvConfig.Spec)
+	return containers
+}
+
+// withMetal3PodAffinity adds a term scheduling the inspector pod on a node
+// running a metal3 pod to the given affinity, which may be nil. The
+// provisioning IP inspector is reached on is only set on that node. As the
+// term is ignored during execution, restartMisplacedInspectorPods moves the
+// inspector pod when the metal3 pod changes nodes.
+func withMetal3PodAffinity(affinity *corev1.Affinity) *corev1.Affinity {
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if affinity.PodAffinity == nil {
+		affinity.PodAffinity = &corev1.PodAffinity{}
+	}
+	affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+		affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+		corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"k8s-app":    metal3AppName,
+					cboLabelName: stateService,
+				},
+			},
+			TopologyKey: "kubernetes.io/hostname",
+		})
+	return affinity
+}
+
+// newMetal3InspectorDeployment returns the deployment running inspector on
+// its own with SplitDeployment. Its pods carry the label selected by the
+// metal3-state service, which they are co-located with, so that the service
+// keeps routing the inspector port.
+func newMetal3InspectorDeployment(info *ProvisioningInfo) (*appsv1.Deployment, error) {
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return nil, err
+	}
+	podSpecLabels := map[string]string{
+		"k8s-app":    inspectorAppName,
+		cboLabelName: stateService,
+	}
+
+	// The pod settings are shared with the metal3 pod, only the containers
+	// and the affinity differ
+	template := newMetal3PodTemplateSpec(info, &podSpecLabels)
+	template.Spec.InitContainers = nil
+	template.Spec.Containers = newMetal3InspectorContainers(info)
+	template.Spec.Affinity = withMetal3PodAffinity(template.Spec.Affinity)
+	if err := withSecretChecksums(info, template); err != nil {
+		return nil, err
+	}
+
+	replicas := int32(1)
+	if info.ProvConfig.Spec.Paused {
+		replicas = 0
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      inspectorDeploymentName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				cboOwnedAnnotation: "",
+			},
+			Labels: map[string]string{
+				"k8s-app":    inspectorAppName,
+				cboLabelName: stateService,
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32Ptr(replicas),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: podSpecLabels,
+			},
+			Template: *template,
+			// The host port prevents two inspector pods from sharing a node
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+		},
+	}, nil
+}
+
+// EnsureMetal3InspectorDeployment creates or updates the inspector deployment
+// with SplitDeployment, and deletes it otherwise.
+func EnsureMetal3InspectorDeployment(info *ProvisioningInfo) (updated bool, err error) {
+	if !UseSplitDeployment(&info.ProvConfig.Spec) {
+		err = DeleteMetal3InspectorDeployment(info)
+		return
+	}
+
+	inspectorDeployment, err := newMetal3InspectorDeployment(info)
+	if err != nil {
+		err = fmt.Errorf("unable to create inspector deployment: %w", err)
+		return
+	}
+	expectedGeneration := resourcemerge.ExpectedDeploymentGeneration(inspectorDeployment, info.ProvConfig.Status.Generations)
+
+	err = controllerutil.SetControllerReference(info.ProvConfig, inspectorDeployment, info.Scheme)
+	if err != nil {
+		err = fmt.Errorf("unable to set controllerReference on deployment: %w", err)
+		return
+	}
+	deployment, updated, err := resourceapply.ApplyDeployment(context.Background(),
+		info.Client.AppsV1(), info.EventRecorder, inspectorDeployment, expectedGeneration)
+	if err != nil {
+		err = fmt.Errorf("unable to apply inspector deployment: %w", err)
+		return
+	}
+	if updated {
+		resourcemerge.SetDeploymentGeneration(&info.ProvConfig.Status.Generations, deployment)
+	}
+	err = restartMisplacedInspectorPods(info)
+	return
+}
+
+// restartMisplacedInspectorPods deletes the inspector pods not running on the
+// node of the metal3 pod. The pod affinity is only enforced at scheduling, so
+// an inspector pod stays behind when the metal3 pod moves to another master,
+// where the provisioning IP moves with it. The deployment then recreates the
+// pod next to the metal3 one.
+func restartMisplacedInspectorPods(info *ProvisioningInfo) error {
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return err
+	}
+	metal3Pod, err := getPod(info.Client.CoreV1(), namespace)
+	if err != nil || metal3Pod.Spec.NodeName == "" {
+		// Wait for the metal3 pod to be scheduled
+		return nil
+	}
+
+	pods, err := info.Client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			"k8s-app":    inspectorAppName,
+			cboLabelName: stateService,
+		}).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list inspector pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Spec.NodeName == "" || pod.Spec.NodeName == metal3Pod.Spec.NodeName {
+			continue
+		}
+		err = info.Client.CoreV1().Pods(namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{})
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to delete inspector pod %s: %w", pod.Name, err)
+		}
+		info.EventRecorder.Eventf("InspectorPodRestarted",
+			"Restarted inspector pod %s on node %s to follow the metal3 pod to node %s",
+			pod.Name, pod.Spec.NodeName, metal3Pod.Spec.NodeName)
+	}
+	return nil
+}
+
+func DeleteMetal3InspectorDeployment(info *ProvisioningInfo) error {
+	namespace, err := info.TargetNamespace()
+	if err != nil {
+		return err
+	}
+	return client.IgnoreNotFound(info.Client.AppsV1().Deployments(namespace).Delete(context.Background(), inspectorDeploymentName, metav1.DeleteOptions{}))
+}
@@ -0,0 +1,241 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestSplitDeploymentContainers(t *testing.T) {
+	containerNames := func(containers []corev1.Container) []string {
+		var names []string
+		for _, container := range containers {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+	portNames := func(container corev1.Container) []string {
+		names := []string{}
+		for _, port := range container.Ports {
+			names = append(names, port.Name)
+		}
+		return names
+	}
+	findContainer := func(containers []corev1.Container, name string) corev1.Container {
+		for _, container := range containers {
+			if container.Name == name {
+				return container
+			}
+		}
+		t.Fatalf("container %s not found", name)
+		return corev1.Container{}
+	}
+
+	tCases := []struct {
+		name              string
+		config            *metal3iov1alpha1.ProvisioningSpec
+		expectedMetal3    []string
+		expectedInspector []string
+	}{
+		{
+			name:           "combined",
+			config:         managedProvisioning().build(),
+			expectedMetal3: []string{"metal3-dnsmasq", "metal3-httpd", "metal3-ironic", "metal3-ironic-inspector", "metal3-ramdisk-logs", "metal3-static-ip-manager"},
+		},
+		{
+			name:              "split",
+			config:            managedProvisioning().SplitDeployment().build(),
+			expectedMetal3:    []string{"metal3-dnsmasq", "metal3-httpd", "metal3-ironic", "metal3-ramdisk-logs", "metal3-static-ip-manager"},
+			expectedInspector: []string{"metal3-ironic-inspector", "metal3-ramdisk-logs"},
+		},
+		{
+			name:              "split with disabled network",
+			config:            disabledProvisioning().SplitDeployment().build(),
+			expectedMetal3:    []string{"metal3-httpd", "metal3-ironic", "metal3-ramdisk-logs"},
+			expectedInspector: []string{"metal3-ironic-inspector", "metal3-ramdisk-logs"},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &Images{Ironic: expectedIronic},
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config},
+			}
+			metal3 := newMetal3Containers(info)
+			inspector := newMetal3InspectorContainers(info)
+			assert.Equal(t, tc.expectedMetal3, containerNames(metal3))
+			assert.Equal(t, tc.expectedInspector, containerNames(inspector))
+
+			httpd := findContainer(metal3, "metal3-httpd")
+			if tc.expectedInspector == nil {
+				assert.Contains(t, portNames(httpd), "inspector")
+				return
+			}
+			assert.NotContains(t, portNames(httpd), "inspector")
+			assert.Contains(t, httpd.Env, corev1.EnvVar{Name: inspectorProxyEnvVar, Value: "false"})
+
+			container := findContainer(inspector, "metal3-ironic-inspector")
+			assert.Equal(t, []string{"inspector"}, portNames(container))
+			assert.Contains(t, container.Env, corev1.EnvVar{Name: inspectorProxyEnvVar, Value: "false"})
+			assert.Contains(t, container.VolumeMounts, inspectorCredentialsMount)
+			for _, env := range container.Env {
+				assert.NotEqual(t, inspectorPrivatePortEnvVar, env.Name)
+			}
+		})
+	}
+}
+
+func TestEnsureMetal3InspectorDeployment(t *testing.T) {
+	kubeClient := fakekube.NewSimpleClientset()
+	info := &ProvisioningInfo{
+		Client:        kubeClient,
+		EventRecorder: events.NewInMemoryRecorder("tests"),
+		Images:        &Images{Ironic: expectedIronic},
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName, UID: "test-uid"},
+			Spec:       *managedProvisioning().SplitDeployment().build(),
+		},
+		Namespace: testNamespace,
+		Scheme:    scheme,
+	}
+
+	_, err := EnsureMetal3Deployment(info)
+	assert.NoError(t, err)
+	updated, err := EnsureMetal3InspectorDeployment(info)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	deployments, err := kubeClient.AppsV1().Deployments(testNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, deployments.Items, 2)
+	for _, deployment := range deployments.Items {
+		assert.Len(t, deployment.OwnerReferences, 1, deployment.Name)
+		assert.Equal(t, metal3iov1alpha1.ProvisioningSingletonName, deployment.OwnerReferences[0].Name, deployment.Name)
+	}
+
+	inspector, err := kubeClient.AppsV1().Deployments(testNamespace).Get(context.Background(), inspectorDeploymentName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, inspector.Spec.Template.Spec.InitContainers)
+	assert.Equal(t, stateService, inspector.Spec.Template.Labels[cboLabelName])
+	assert.Len(t, inspector.Spec.Template.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution, 1)
+
+	// Both deployments select disjoint pods
+	metal3, err := kubeClient.AppsV1().Deployments(testNamespace).Get(context.Background(), baremetalDeploymentName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, metal3.Spec.Selector.MatchLabels, inspector.Spec.Selector.MatchLabels)
+
+	// Leaving the split mode removes the inspector deployment
+	info.ProvConfig.Spec.SplitDeployment = false
+	_, err = EnsureMetal3InspectorDeployment(info)
+	assert.NoError(t, err)
+	deployments, err = kubeClient.AppsV1().Deployments(testNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, deployments.Items, 1)
+	assert.Equal(t, baremetalDeploymentName, deployments.Items[0].Name)
+}
+
+func TestInspectorRamdiskLogsVolumeLayout(t *testing.T) {
+	info := &ProvisioningInfo{
+		Client:     fakekube.NewSimpleClientset(),
+		Images:     &Images{Ironic: expectedIronic},
+		ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().SplitDeployment().build()},
+		Namespace:  testNamespace,
+	}
+	deployment, err := newMetal3InspectorDeployment(info)
+	assert.NoError(t, err)
+	spec := deployment.Spec.Template.Spec
+
+	mountedVolume := func(containerName, path string) string {
+		for _, container := range spec.Containers {
+			if container.Name != containerName {
+				continue
+			}
+			for _, mount := range container.VolumeMounts {
+				if mount.MountPath == path {
+					return mount.Name
+				}
+			}
+		}
+		t.Fatalf("%s not mounted at %s", containerName, path)
+		return ""
+	}
+
+	// The log watcher reads the ramdisk logs from the volume inspector
+	// writes them to, which is defined in the inspector pod itself
+	inspectorVolume := mountedVolume(inspectorContainerName, "/shared")
+	assert.Equal(t, inspectorVolume, mountedVolume(ramdiskLogsContainerName, "/shared/log/ironic-inspector/ramdisk"))
+	found := false
+	for _, volume := range spec.Volumes {
+		if volume.Name == inspectorVolume {
+			found = true
+			assert.NotNil(t, volume.EmptyDir)
+		}
+	}
+	assert.True(t, found, "volume %s not defined", inspectorVolume)
+}
+
+func TestRestartMisplacedInspectorPods(t *testing.T) {
+	pod := func(name, app, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: testNamespace,
+				Labels: map[string]string{
+					"k8s-app":    app,
+					cboLabelName: stateService,
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+
+	tCases := []struct {
+		name         string
+		metal3Node   string
+		expectedPods []string
+	}{
+		{
+			name:         "metal3 pod moved",
+			metal3Node:   "master-1",
+			expectedPods: []string{"metal3", "metal3-inspector-pending", "metal3-inspector-same-node"},
+		},
+		{
+			name:         "metal3 pod not scheduled",
+			metal3Node:   "",
+			expectedPods: []string{"metal3", "metal3-inspector-old-node", "metal3-inspector-pending", "metal3-inspector-same-node"},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeClient := fakekube.NewSimpleClientset(
+				pod("metal3", metal3AppName, tc.metal3Node),
+				pod("metal3-inspector-old-node", inspectorAppName, "master-0"),
+				pod("metal3-inspector-same-node", inspectorAppName, "master-1"),
+				pod("metal3-inspector-pending", inspectorAppName, ""),
+			)
+			info := &ProvisioningInfo{
+				Client:        kubeClient,
+				EventRecorder: events.NewInMemoryRecorder("tests"),
+				ProvConfig:    &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().SplitDeployment().build()},
+				Namespace:     testNamespace,
+			}
+
+			assert.NoError(t, restartMisplacedInspectorPods(info))
+
+			pods, err := kubeClient.CoreV1().Pods(testNamespace).List(context.Background(), metav1.ListOptions{})
+			assert.NoError(t, err)
+			var names []string
+			for _, pod := range pods.Items {
+				names = append(names, pod.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedPods, names)
+		})
+	}
+}
@@ -35,7 +35,9 @@ const (
 	inspectorProxyPortEnvVar    = "IRONIC_INSPECTOR_PROXY_PORT"
 )
 
-func createContainerIronicProxy(ironicIP string, images *Images) corev1.Container {
+func createContainerIronicProxy(ironicIP string, images *Images, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
+	ironicPort := getIronicPort(config)
+	inspectorPort := getInspectorPort(config)
 	container := corev1.Container{
 		Name:            "ironic-proxy",
 		Image:           images.Ironic,
@@ -53,23 +55,23 @@ func createContainerIronicProxy(ironicIP string, images *Images) corev1.Containe
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "ironic-proxy",
-				ContainerPort: int32(baremetalIronicPort),
-				HostPort:      int32(baremetalIronicPort),
+				ContainerPort: int32(ironicPort),
+				HostPort:      int32(ironicPort),
 			},
 			{
 				Name:          "inspector-proxy",
-				ContainerPort: int32(baremetalIronicInspectorPort),
-				HostPort:      int32(baremetalIronicInspectorPort),
+				ContainerPort: int32(inspectorPort),
+				HostPort:      int32(inspectorPort),
 			},
 		},
 		Env: []corev1.EnvVar{
 			{
 				Name:  ironicProxyPortEnvVar,
-				Value: fmt.Sprint(baremetalIronicPort),
+				Value: fmt.Sprint(ironicPort),
 			},
 			{
 				Name:  inspectorProxyPortEnvVar,
-				Value: fmt.Sprint(baremetalIronicInspectorPort),
+				Value: fmt.Sprint(inspectorPort),
 			},
 			{
 				Name:  ironicUpstreamIPEnvVar,
@@ -116,7 +118,7 @@ func newIronicProxyPodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateS
 
 	containers := []corev1.Container{
 		// Even in a dual-stack environment, we don't really care which IP address to use since both are accessible internally.
-		createContainerIronicProxy(ironicIPs[0], info.Images),
+		createContainerIronicProxy(ironicIPs[0], info.Images, &info.ProvConfig.Spec),
 	}
 
 	tolerations := []corev1.Toleration{
@@ -159,7 +161,7 @@ func newIronicProxyPodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateS
 					Name: ironicTlsVolume,
 					VolumeSource: corev1.VolumeSource{
 						Secret: &corev1.SecretVolumeSource{
-							SecretName: tlsSecretName,
+							SecretName: getTlsSecretName(&info.ProvConfig.Spec),
 						},
 					},
 				},
@@ -167,24 +169,13 @@ func newIronicProxyPodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateS
 					Name: inspectorTlsVolume,
 					VolumeSource: corev1.VolumeSource{
 						Secret: &corev1.SecretVolumeSource{
-							SecretName: tlsSecretName,
-						},
-					},
-				},
-				{
-					Name: "trusted-ca",
-					VolumeSource: corev1.VolumeSource{
-						ConfigMap: &corev1.ConfigMapVolumeSource{
-							Items: []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}},
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: externalTrustBundleConfigMapName,
-							},
-							Optional: pointer.BoolPtr(true),
+							SecretName: getTlsSecretName(&info.ProvConfig.Spec),
 						},
 					},
 				},
+				newTrustedCAVolume(&info.ProvConfig.Spec),
 			},
-			Containers:        injectProxyAndCA(containers, info.Proxy),
+			Containers:        withoutHostPorts(injectProxyAndCA(containers, info.Proxy, &info.ProvConfig.Spec), &info.ProvConfig.Spec),
 			HostNetwork:       true,
 			DNSPolicy:         corev1.DNSClusterFirstWithHostNet,
 			PriorityClassName: "system-node-critical",
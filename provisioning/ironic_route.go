@@ -0,0 +1,141 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	ironicRouteName   = "metal3-ironic"
+	routeGroupVersion = "route.openshift.io/v1"
+	routeKind         = "Route"
+	routeResource     = "routes"
+)
+
+var routeGVR = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: routeResource}
+
+// newIronicRoute returns the Route exposing the Ironic API of the
+// metal3-state service. TLS is passed through to Ironic, so that clients
+// verify its certificate and can authenticate with their own.
+func newIronicRoute(info *ProvisioningInfo) *unstructured.Unstructured {
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"to": map[string]interface{}{
+					"kind": "Service",
+					"name": stateService,
+				},
+				"port": map[string]interface{}{
+					"targetPort": "ironic",
+				},
+				"tls": map[string]interface{}{
+					"termination":                   "passthrough",
+					"insecureEdgeTerminationPolicy": "None",
+				},
+			},
+		},
+	}
+	route.SetAPIVersion(routeGroupVersion)
+	route.SetKind(routeKind)
+	route.SetName(ironicRouteName)
+	route.SetNamespace(info.Namespace)
+	route.SetLabels(map[string]string{
+		cboLabelName: stateService,
+	})
+	return route
+}
+
+// applyRoute creates or updates the route. The host is defaulted by the API
+// server when not requested, so it is kept from the existing route.
+func applyRoute(info *ProvisioningInfo, required *unstructured.Unstructured) (bool, error) {
+	routes := info.DynamicClient.Resource(routeGVR).Namespace(required.GetNamespace())
+	existing, err := routes.Get(context.Background(), required.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = routes.Create(context.Background(), required, metav1.CreateOptions{})
+		if err != nil {
+			info.EventRecorder.Warningf("RouteCreateFailed", "Failed to create Route %s/%s: %v", required.GetNamespace(), required.GetName(), err)
+			return false, err
+		}
+		info.EventRecorder.Eventf("RouteCreated", "Created Route %s/%s because it was missing", required.GetNamespace(), required.GetName())
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if host, found, _ := unstructured.NestedString(existing.Object, "spec", "host"); found {
+		if err := unstructured.SetNestedField(required.Object, host, "spec", "host"); err != nil {
+			return false, err
+		}
+	}
+	if equality.Semantic.DeepEqual(existing.Object["spec"], required.Object["spec"]) &&
+		equality.Semantic.DeepEqual(existing.GetLabels(), required.GetLabels()) &&
+		equality.Semantic.DeepEqual(existing.GetOwnerReferences(), required.GetOwnerReferences()) {
+		return false, nil
+	}
+
+	required.SetResourceVersion(existing.GetResourceVersion())
+	_, err = routes.Update(context.Background(), required, metav1.UpdateOptions{})
+	if err != nil {
+		info.EventRecorder.Warningf("RouteUpdateFailed", "Failed to update Route %s/%s: %v", required.GetNamespace(), required.GetName(), err)
+		return false, err
+	}
+	info.EventRecorder.Eventf("RouteUpdated", "Updated Route %s/%s because it changed", required.GetNamespace(), required.GetName())
+	return true, nil
+}
+
+// EnsureIronicRoute creates or updates the Ironic route with
+// ExposeIronicRoute, and deletes it otherwise. Nothing is done on clusters
+// without the Route API.
+func EnsureIronicRoute(info *ProvisioningInfo) (updated bool, err error) {
+	available, err := apiResourceAvailable(info.Client.Discovery(), routeGroupVersion, routeResource)
+	if err != nil {
+		err = fmt.Errorf("unable to discover %s resources: %w", routeGroupVersion, err)
+		return
+	}
+	if !available {
+		if info.ProvConfig.Spec.ExposeIronicRoute {
+			klog.Info("Route API not present, skipping the Ironic route")
+		}
+		return
+	}
+	if !info.ProvConfig.Spec.ExposeIronicRoute {
+		err = deleteIronicRoute(info)
+		return
+	}
+
+	route := newIronicRoute(info)
+	err = controllerutil.SetControllerReference(info.ProvConfig, route, info.Scheme)
+	if err != nil {
+		err = fmt.Errorf("unable to set controllerReference on route: %w", err)
+		return
+	}
+
+	updated, err = applyRoute(info, route)
+	if err != nil {
+		err = fmt.Errorf("unable to apply Ironic route: %w", err)
+	}
+	return
+}
+
+func deleteIronicRoute(info *ProvisioningInfo) error {
+	return client.IgnoreNotFound(info.DynamicClient.Resource(routeGVR).Namespace(info.Namespace).Delete(context.Background(), ironicRouteName, metav1.DeleteOptions{}))
+}
+
+// DeleteIronicRoute deletes the Ironic route, if the Route API is present.
+func DeleteIronicRoute(info *ProvisioningInfo) error {
+	available, err := apiResourceAvailable(info.Client.Discovery(), routeGroupVersion, routeResource)
+	if err != nil || !available {
+		return err
+	}
+	return deleteIronicRoute(info)
+}
@@ -0,0 +1,78 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestNewIronicRoute(t *testing.T) {
+	info := &ProvisioningInfo{
+		Namespace: testNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+		},
+	}
+	route := newIronicRoute(info)
+
+	assert.Equal(t, "route.openshift.io/v1", route.GetAPIVersion())
+	assert.Equal(t, "Route", route.GetKind())
+	assert.Equal(t, testNamespace, route.GetNamespace())
+
+	for _, tc := range []struct {
+		path     []string
+		expected string
+	}{
+		{[]string{"spec", "to", "kind"}, "Service"},
+		{[]string{"spec", "to", "name"}, stateService},
+		{[]string{"spec", "port", "targetPort"}, "ironic"},
+		{[]string{"spec", "tls", "termination"}, "passthrough"},
+		{[]string{"spec", "tls", "insecureEdgeTerminationPolicy"}, "None"},
+	} {
+		value, found, err := unstructured.NestedString(route.Object, tc.path...)
+		assert.NoError(t, err)
+		assert.True(t, found, tc.path)
+		assert.Equal(t, tc.expected, value, tc.path)
+	}
+	// The host is left for the router to assign
+	_, found, _ := unstructured.NestedString(route.Object, "spec", "host")
+	assert.False(t, found)
+
+	assert.NoError(t, controllerutil.SetControllerReference(info.ProvConfig, route, scheme))
+	if assert.Len(t, route.GetOwnerReferences(), 1) {
+		ownerRef := route.GetOwnerReferences()[0]
+		assert.Equal(t, metal3iov1alpha1.ProvisioningSingletonName, ownerRef.Name)
+		assert.Equal(t, "Provisioning", ownerRef.Kind)
+		assert.True(t, *ownerRef.Controller)
+	}
+}
+
+func TestEnsureIronicRouteWithoutRouteAPI(t *testing.T) {
+	kubeClient := fakekube.NewSimpleClientset()
+	// No route.openshift.io resources are registered with discovery
+	kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{}
+
+	info := &ProvisioningInfo{
+		Client:    kubeClient,
+		Namespace: testNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+			Spec:       metal3iov1alpha1.ProvisioningSpec{ExposeIronicRoute: true},
+		},
+		Scheme:        scheme,
+		EventRecorder: events.NewLoggingEventRecorder("tests"),
+	}
+
+	updated, err := EnsureIronicRoute(info)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	assert.NoError(t, DeleteIronicRoute(info))
+}
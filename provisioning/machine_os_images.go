@@ -1,6 +1,9 @@
 package provisioning
 
 import (
+	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/pointer"
@@ -39,3 +42,34 @@ func createInitContainerMachineOSImages(info *ProvisioningInfo, whichImages stri
 	}
 	return container
 }
+
+// createInitContainerMachineOSImagesVerify checks that the files extracted by
+// the machine-os-images init container exist and are not empty, so that a
+// silent extraction failure stops the pod at initialization rather than
+// leaving the main containers serving a broken image.
+func createInitContainerMachineOSImagesVerify(info *ProvisioningInfo, dest corev1.VolumeMount, files ...string) corev1.Container {
+	script := fmt.Sprintf(`for f in %s; do
+  if [ ! -s "$f" ]; then
+    echo "$f is missing or empty, the machine OS images were not extracted" >&2
+    exit 1
+  fi
+done`, strings.Join(files, " "))
+
+	return corev1.Container{
+		Name:            "machine-os-images-verify",
+		Image:           info.Images.MachineOSImages,
+		Command:         []string{"/bin/sh", "-c", script},
+		VolumeMounts:    []corev1.VolumeMount{dest},
+		ImagePullPolicy: "IfNotPresent",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			// Needed for hostPath image volume mount
+			Privileged: pointer.BoolPtr(true),
+		},
+	}
+}
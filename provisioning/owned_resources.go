@@ -0,0 +1,170 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func isCBOOwned(meta metav1.Object) bool {
+	if _, owned := meta.GetAnnotations()[cboOwnedAnnotation]; owned {
+		return true
+	}
+	_, labelled := meta.GetLabels()[cboLabelName]
+	return labelled
+}
+
+// DeleteMetal3Resources removes every resource CBO creates for the
+// Provisioning CR: the known secrets, services, deployments, daemonsets and
+// the validating webhook, then any remaining CBO-owned resource. Resources
+// that are already gone are ignored, so it is safe to call it repeatedly.
+func DeleteMetal3Resources(info *ProvisioningInfo) error {
+	for _, step := range []struct {
+		description string
+		delete      func(*ProvisioningInfo) error
+	}{
+		{"one or more metal3 secrets", DeleteAllSecrets},
+		{"validatingwebhook and service", DeleteValidatingWebhook},
+		{"metal3 deployment", DeleteMetal3Deployment},
+		{"metal3 inspector deployment", DeleteMetal3InspectorDeployment},
+		{"metal3 service", DeleteMetal3StateService},
+		{"metal3 image cache", DeleteImageCache},
+		{"metal3 image customization service", DeleteImageCustomizationService},
+		{"metal3 image customization deployment", DeleteImageCustomizationDeployment},
+		{"ironic proxy", DeleteIronicProxy},
+		{"ironic route", DeleteIronicRoute},
+		{"baremetal-operator metrics", DeleteBaremetalOperatorMetrics},
+		{"remaining metal3 resources", DeleteOwnedResources},
+	} {
+		if err := step.delete(info); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", step.description, err)
+		}
+	}
+	return nil
+}
+
+// DeleteOwnedResources removes every Deployment, DaemonSet and Service in the
+// target namespace that carries the cboOwnedAnnotation or the cboLabelName
+// label. It is meant to be called when the Provisioning CR is deleted, to
+// catch resources that are not garbage collected through their owner
+// reference. Resources that are already gone are ignored, so it is safe to
+// call it repeatedly.
+func DeleteOwnedResources(info *ProvisioningInfo) error {
+	ctx := context.Background()
+
+	resources, err := listNamespaceResources(ctx, info)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		if !isCBOOwned(resource.meta) {
+			continue
+		}
+		if err := resource.delete(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currentComponents are the cboLabelName values of the resources managed by
+// this version of CBO.
+var currentComponents = map[string]bool{
+	stateService:              true,
+	bmoServiceName:            true,
+	imageCacheService:         true,
+	imageCustomizationService: true,
+	ironicProxyService:        true,
+}
+
+// isStale reports whether a resource was created by a prior CBO version: it
+// carries the cboOwnedAnnotation but no cboLabelName label of a current
+// component. The metal3 and baremetal-operator deployments are left to their
+// Ensure functions, which update them in place.
+func isStale(meta metav1.Object) bool {
+	if _, owned := meta.GetAnnotations()[cboOwnedAnnotation]; !owned {
+		return false
+	}
+	if meta.GetName() == baremetalDeploymentName || meta.GetName() == bmoDeploymentName {
+		return false
+	}
+	return !currentComponents[meta.GetLabels()[cboLabelName]]
+}
+
+// DeleteStaleResources garbage-collects the resources left over by prior CBO
+// versions, which the current selectors no longer match, recording an event
+// for each of them.
+func DeleteStaleResources(info *ProvisioningInfo) (updated bool, err error) {
+	ctx := context.Background()
+
+	resources, err := listNamespaceResources(ctx, info)
+	if err != nil {
+		return false, err
+	}
+	for _, resource := range resources {
+		if !isStale(resource.meta) {
+			continue
+		}
+		info.EventRecorder.Eventf("StaleResourceDeleted", "Deleting %s %s/%s left over by a prior version",
+			resource.kind, info.Namespace, resource.meta.GetName())
+		if err := resource.delete(ctx); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// namespaceResource is a resource of the target namespace CBO may delete
+type namespaceResource struct {
+	kind   string
+	meta   metav1.Object
+	delete func(context.Context) error
+}
+
+// listNamespaceResources lists the Deployments, DaemonSets and Services of the
+// target namespace. Deleting one of them ignores NotFound errors.
+func listNamespaceResources(ctx context.Context, info *ProvisioningInfo) ([]namespaceResource, error) {
+	var resources []namespaceResource
+	deleter := func(kind, name string, del func(context.Context, string, metav1.DeleteOptions) error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			if err := client.IgnoreNotFound(del(ctx, name, metav1.DeleteOptions{})); err != nil {
+				return fmt.Errorf("unable to delete %s %s: %w", kind, name, err)
+			}
+			return nil
+		}
+	}
+
+	deploymentClient := info.Client.AppsV1().Deployments(info.Namespace)
+	deployments, err := deploymentClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		name := deployments.Items[i].Name
+		resources = append(resources, namespaceResource{"deployment", &deployments.Items[i], deleter("deployment", name, deploymentClient.Delete)})
+	}
+
+	daemonSetClient := info.Client.AppsV1().DaemonSets(info.Namespace)
+	daemonSets, err := daemonSetClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		name := daemonSets.Items[i].Name
+		resources = append(resources, namespaceResource{"daemonset", &daemonSets.Items[i], deleter("daemonset", name, daemonSetClient.Delete)})
+	}
+
+	serviceClient := info.Client.CoreV1().Services(info.Namespace)
+	services, err := serviceClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list services: %w", err)
+	}
+	for i := range services.Items {
+		name := services.Items[i].Name
+		resources = append(resources, namespaceResource{"service", &services.Items[i], deleter("service", name, serviceClient.Delete)})
+	}
+
+	return resources, nil
+}
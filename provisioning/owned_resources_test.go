@@ -0,0 +1,169 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestDeleteOwnedResources(t *testing.T) {
+	namespace := "openshift-machine-api"
+	owned := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{cboOwnedAnnotation: ""},
+		}
+	}
+	kubeClient := fakekube.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: owned(baremetalDeploymentName)},
+		&appsv1.Deployment{ObjectMeta: owned(bmoDeploymentName)},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: namespace}},
+		&appsv1.DaemonSet{ObjectMeta: owned("metal3-stray")},
+		&corev1.Service{ObjectMeta: owned("metal3-metrics")},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: namespace}},
+	)
+	info := &ProvisioningInfo{
+		Client:    kubeClient,
+		Namespace: namespace,
+	}
+
+	assert.NoError(t, DeleteOwnedResources(info))
+	// A second call must be a no-op
+	assert.NoError(t, DeleteOwnedResources(info))
+
+	deployments, err := kubeClient.AppsV1().Deployments(namespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, deployments.Items, 1)
+	assert.Equal(t, "unrelated", deployments.Items[0].Name)
+
+	daemonSets, err := kubeClient.AppsV1().DaemonSets(namespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, daemonSets.Items)
+
+	services, err := kubeClient.CoreV1().Services(namespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, services.Items, 1)
+	assert.Equal(t, "unrelated", services.Items[0].Name)
+}
+
+func TestDeleteMetal3Resources(t *testing.T) {
+	namespace := "openshift-machine-api"
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace}
+	}
+	kubeClient := fakekube.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: objectMeta(ironicSecretName)},
+		&appsv1.Deployment{ObjectMeta: objectMeta(baremetalDeploymentName)},
+		&appsv1.Deployment{ObjectMeta: objectMeta(imageCustomizationDeploymentName)},
+		&appsv1.DaemonSet{ObjectMeta: objectMeta(imageCacheService)},
+		&corev1.Service{ObjectMeta: objectMeta(stateService)},
+		&corev1.Service{ObjectMeta: objectMeta(bmoMetricsServiceName)},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Name:      "metal3-labelled",
+			Namespace: namespace,
+			Labels:    map[string]string{cboLabelName: "metal3-labelled"},
+		}},
+		&corev1.Service{ObjectMeta: objectMeta("unrelated")},
+	)
+	info := &ProvisioningInfo{
+		Client:    kubeClient,
+		Namespace: namespace,
+	}
+
+	assert.NoError(t, DeleteMetal3Resources(info))
+
+	deleted := []string{}
+	for _, action := range kubeClient.Actions() {
+		if deleteAction, ok := action.(k8stesting.DeleteAction); ok {
+			deleted = append(deleted, action.GetResource().Resource+"/"+deleteAction.GetName())
+		}
+	}
+	for _, expected := range []string{
+		"secrets/" + ironicSecretName,
+		"deployments/" + baremetalDeploymentName,
+		"deployments/" + imageCustomizationDeploymentName,
+		"daemonsets/" + imageCacheService,
+		"daemonsets/" + ironicProxyService,
+		"services/" + stateService,
+		"services/" + bmoMetricsServiceName,
+		"services/" + validatingWebhookService,
+		"services/metal3-labelled",
+		"validatingwebhookconfigurations/" + validatingWebhookConfigurationName,
+	} {
+		assert.Contains(t, deleted, expected)
+	}
+	assert.NotContains(t, deleted, "services/unrelated")
+
+	// A second call must be a no-op
+	assert.NoError(t, DeleteMetal3Resources(info))
+
+	services, err := kubeClient.CoreV1().Services(namespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, services.Items, 1)
+	assert.Equal(t, "unrelated", services.Items[0].Name)
+}
+
+func TestDeleteStaleResources(t *testing.T) {
+	namespace := "openshift-machine-api"
+	kubeClient := fakekube.NewSimpleClientset(
+		// Left over by a prior version, with only the owned annotation
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:        "metal3-legacy",
+			Namespace:   namespace,
+			Annotations: map[string]string{cboOwnedAnnotation: ""},
+		}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:        bmoDeploymentName,
+			Namespace:   namespace,
+			Annotations: map[string]string{cboOwnedAnnotation: ""},
+			Labels:      map[string]string{cboLabelName: bmoServiceName},
+		}},
+		// Managed in place by EnsureMetal3Deployment
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:        baremetalDeploymentName,
+			Namespace:   namespace,
+			Annotations: map[string]string{cboOwnedAnnotation: ""},
+		}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Name:      stateService,
+			Namespace: namespace,
+			Labels:    map[string]string{cboLabelName: stateService},
+		}},
+	)
+	info := &ProvisioningInfo{
+		Client:        kubeClient,
+		Namespace:     namespace,
+		EventRecorder: events.NewInMemoryRecorder("tests"),
+	}
+
+	updated, err := DeleteStaleResources(info)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	deployments, err := kubeClient.AppsV1().Deployments(namespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	names := []string{}
+	for _, deployment := range deployments.Items {
+		names = append(names, deployment.Name)
+	}
+	assert.ElementsMatch(t, []string{bmoDeploymentName, baremetalDeploymentName, "unrelated"}, names)
+
+	services, err := kubeClient.CoreV1().Services(namespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, services.Items, 1)
+
+	recorded := info.EventRecorder.(events.InMemoryRecorder).Events()
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, "StaleResourceDeleted", recorded[0].Reason)
+	assert.Contains(t, recorded[0].Message, "metal3-legacy")
+}
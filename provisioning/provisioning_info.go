@@ -1,7 +1,12 @@
 package provisioning
 
 import (
+	"fmt"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -44,6 +49,17 @@ type ProvisioningInfo struct {
 	MasterMacAddresses      []string
 	SSHKey                  string
 	BaremetalWebhookEnabled bool
+	SingleNodeTopology      bool
 	OSClient                osclientset.Interface
+	DynamicClient           dynamic.Interface
 	ResourceCache           resourceapply.ResourceCache
 }
+
+// TargetNamespace returns the namespace the metal3 resources are deployed
+// to, failing if it is not a valid namespace name.
+func (info *ProvisioningInfo) TargetNamespace() (string, error) {
+	if errs := validation.IsDNS1123Label(info.Namespace); len(errs) > 0 {
+		return "", fmt.Errorf("invalid target namespace %q: %s", info.Namespace, strings.Join(errs, ", "))
+	}
+	return info.Namespace, nil
+}
@@ -20,8 +20,8 @@ const (
 )
 
 func newMetal3StateService(info *ProvisioningInfo) *corev1.Service {
-	port, _ := strconv.Atoi(baremetalHttpPort)             // #nosec
-	httpsPort, _ := strconv.Atoi(baremetalVmediaHttpsPort) // #nosec
+	port, _ := strconv.Atoi(getHttpPort(&info.ProvConfig.Spec)) // #nosec
+	httpsPort, _ := strconv.Atoi(baremetalVmediaHttpsPort)      // #nosec
 	ironicPort, inspectorPort := getControlPlanePorts(info)
 
 	ports := []corev1.ServicePort{
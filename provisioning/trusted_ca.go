@@ -0,0 +1,70 @@
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	trustedCABundleKey      = "ca-bundle.crt"
+	proxyTrustedCANamespace = "openshift-config"
+)
+
+// parseCABundle returns the PEM blocks of a CA bundle. It fails when any
+// block cannot be decoded or is not a valid certificate, as the consumers
+// of the bundle would otherwise silently skip it.
+func parseCABundle(bundle string) ([]*pem.Block, error) {
+	blocks := []*pem.Block{}
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type %s", block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	// pem.Decode skips over blocks it cannot decode
+	if found := bytes.Count([]byte(bundle), []byte("-----BEGIN ")); found != len(blocks) {
+		return nil, fmt.Errorf("%d of %d PEM blocks could not be decoded", found-len(blocks), found)
+	}
+	return blocks, nil
+}
+
+// CheckProxyTrustedCA validates the trusted CA bundle the cluster proxy
+// references, which the cluster network operator injects into the trusted CA
+// ConfigMap mounted by the metal3 components. A malformed bundle silently
+// breaks TLS to mirrored registries. It returns a message naming the
+// offending bundle, and an empty message when the proxy has no trusted CA
+// or it is valid. The injected system trust store is not checked, it may
+// hold roots that crypto/x509 rejects but other TLS clients accept.
+func CheckProxyTrustedCA(info *ProvisioningInfo) (string, error) {
+	if info.Proxy == nil || info.Proxy.Spec.TrustedCA.Name == "" {
+		return "", nil
+	}
+
+	name := info.Proxy.Spec.TrustedCA.Name
+	proxyCA, err := info.Client.CoreV1().ConfigMaps(proxyTrustedCANamespace).Get(context.Background(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("ConfigMap %s/%s referenced by the trustedCA of the cluster proxy not found", proxyTrustedCANamespace, name), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to get the trusted CA bundle of the cluster proxy: %w", err)
+	}
+	if _, err := parseCABundle(proxyCA.Data[trustedCABundleKey]); err != nil {
+		return fmt.Sprintf("invalid %s in ConfigMap %s/%s: %s", trustedCABundleKey, proxyTrustedCANamespace, name, err), nil
+	}
+	return "", nil
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
@@ -15,7 +17,114 @@ import (
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
 
-func getPod(podClient coreclientv1.PodsGetter, targetNamespace string) (corev1.Pod, error) {
+// IronicReadyState tells whether the Ironic API of the metal3 pod is serving
+type IronicReadyState string
+
+const (
+	IronicReady       IronicReadyState = "Ready"
+	IronicNotReady    IronicReadyState = "NotReady"
+	IronicPodNotFound IronicReadyState = "PodNotFound"
+)
+
+// GetIronicReadyState reports whether the Ironic container of the metal3 pod
+// is Ready, i.e. Ironic finished starting up, including its database
+// migrations, and is serving its API.
+func GetIronicReadyState(info *ProvisioningInfo) (IronicReadyState, error) {
+	pod, err := getPod(info.Client.CoreV1(), info.Namespace)
+	if err != nil {
+		return IronicNotReady, err
+	}
+	if pod.Name == "" {
+		return IronicPodNotFound, nil
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == ironicContainerName && status.Ready {
+			return IronicReady, nil
+		}
+	}
+	return IronicNotReady, nil
+}
+
+// InitializationState tells whether the init containers of the metal3 pod,
+// which download the machine OS images, have all completed
+type InitializationState string
+
+const (
+	InitializationComplete    InitializationState = "Complete"
+	InitializationInProgress  InitializationState = "InProgress"
+	InitializationPodNotFound InitializationState = "PodNotFound"
+)
+
+// GetInitializationState reports whether every init container of the metal3
+// pod has terminated successfully.
+func GetInitializationState(info *ProvisioningInfo) (InitializationState, error) {
+	pod, err := getPod(info.Client.CoreV1(), info.Namespace)
+	if err != nil {
+		return InitializationInProgress, err
+	}
+	if pod.Name == "" {
+		return InitializationPodNotFound, nil
+	}
+	succeeded := 0
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode == 0 {
+			succeeded++
+		}
+	}
+	if succeeded < len(pod.Spec.InitContainers) {
+		return InitializationInProgress, nil
+	}
+	return InitializationComplete, nil
+}
+
+// initContainerFailure describes the first init container of the pod stuck
+// waiting, e.g. in ImagePullBackOff, or exited with an error. It returns an
+// empty string when all init containers are progressing.
+func initContainerFailure(pod corev1.Pod) string {
+	for _, status := range pod.Status.InitContainerStatuses {
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+			return fmt.Sprintf("init container %s is waiting: %s", status.Name, status.State.Waiting.Reason)
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+			return fmt.Sprintf("init container %s exited with code %d: %s", status.Name, status.State.Terminated.ExitCode, status.State.Terminated.Reason)
+		}
+	}
+	return ""
+}
+
+// crashLoopingContainer describes the first container of the pod in
+// CrashLoopBackOff, with the reason of its last termination. It returns an
+// empty string when no container is crashlooping.
+func crashLoopingContainer(pod corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting == nil || status.State.Waiting.Reason != "CrashLoopBackOff" {
+			continue
+		}
+		if last := status.LastTerminationState.Terminated; last != nil {
+			return fmt.Sprintf("container %s is in CrashLoopBackOff, last exited with code %d: %s", status.Name, last.ExitCode, last.Reason)
+		}
+		return fmt.Sprintf("container %s is in CrashLoopBackOff", status.Name)
+	}
+	return ""
+}
+
+// stuckTerminatingPod describes the first pod still terminating more than
+// podTerminationStuckThreshold past its deletion deadline, which happens when
+// its node cannot be reached or drained. It returns an empty string when no
+// pod is stuck.
+func stuckTerminatingPod(pods []corev1.Pod) string {
+	for _, pod := range pods {
+		// The deletion timestamp already includes the grace period
+		if pod.DeletionTimestamp == nil || time.Since(pod.DeletionTimestamp.Time) < podTerminationStuckThreshold {
+			continue
+		}
+		return fmt.Sprintf("old pod %s is stuck terminating on node %s since %s, check that the node is reachable or force delete the pod",
+			pod.Name, pod.Spec.NodeName, pod.DeletionTimestamp.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+func listMetal3Pods(podClient coreclientv1.PodsGetter, targetNamespace string) ([]corev1.Pod, error) {
 	labelSelector := &metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			"k8s-app":    metal3AppName,
@@ -24,7 +133,7 @@ func getPod(podClient coreclientv1.PodsGetter, targetNamespace string) (corev1.P
 
 	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
 	if err != nil {
-		return corev1.Pod{}, err
+		return nil, err
 	}
 
 	listOptions := metav1.ListOptions{
@@ -32,6 +141,14 @@ func getPod(podClient coreclientv1.PodsGetter, targetNamespace string) (corev1.P
 	}
 
 	podList, err := podClient.Pods(targetNamespace).List(context.Background(), listOptions)
+	if err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
+
+func getPod(podClient coreclientv1.PodsGetter, targetNamespace string) (corev1.Pod, error) {
+	podList, err := listMetal3Pods(podClient, targetNamespace)
 	if err != nil {
 		return corev1.Pod{}, err
 	}
@@ -39,7 +156,7 @@ func getPod(podClient coreclientv1.PodsGetter, targetNamespace string) (corev1.P
 	// On fail-over, two copies of the pod will be present: the old
 	// Terminating one and the new Running one. Ignore terminating pods.
 	var pods []corev1.Pod
-	for _, pod := range podList.Items {
+	for _, pod := range podList {
 		if pod.DeletionTimestamp == nil {
 			pods = append(pods, pod)
 		}
@@ -75,6 +192,19 @@ func getPodIPs(podClient coreclientv1.PodsGetter, targetNamespace string) (ips [
 	return
 }
 
+// IsSingleNodeTopology reports whether the control plane runs on a single
+// node. A missing infrastructure object is treated as a multi-node cluster.
+func IsSingleNodeTopology(osclient osclientset.Interface) (bool, error) {
+	infra, err := osclient.ConfigV1().Infrastructures().Get(context.Background(), "cluster", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to get the 'cluster' object from infrastructure API: %w", err)
+	}
+	return infra.Status.ControlPlaneTopology == osconfigv1.SingleReplicaTopologyMode, nil
+}
+
 // getServerInternalIPs returns virtual IPs on which Kubernetes is accessible.
 // These are the IPs on which the proxied services (currently Ironic and Inspector) should be accessed by external consumers.
 func getServerInternalIPs(osclient osclientset.Interface) ([]string, error) {
@@ -0,0 +1,173 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
+)
+
+func TestGetIronicReadyState(t *testing.T) {
+	metal3Pod := func(ironicReady bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "metal3-12345",
+				Namespace: testNamespace,
+				Labels: map[string]string{
+					"k8s-app":    metal3AppName,
+					cboLabelName: stateService,
+				},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "metal3-httpd", Ready: true},
+					{Name: "metal3-ironic", Ready: ironicReady},
+				},
+			},
+		}
+	}
+	tCases := []struct {
+		name          string
+		objects       []runtime.Object
+		expectedState IronicReadyState
+	}{
+		{
+			name:          "no pod",
+			expectedState: IronicPodNotFound,
+		},
+		{
+			name:          "ironic starting",
+			objects:       []runtime.Object{metal3Pod(false)},
+			expectedState: IronicNotReady,
+		},
+		{
+			name:          "ironic ready",
+			objects:       []runtime.Object{metal3Pod(true)},
+			expectedState: IronicReady,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Client:    fakekube.NewSimpleClientset(tc.objects...),
+				Namespace: testNamespace,
+			}
+			state, err := GetIronicReadyState(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedState, state)
+		})
+	}
+}
+
+func TestGetInitializationState(t *testing.T) {
+	terminated := func(exitCode int32) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}}
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	metal3Pod := func(statuses ...corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "metal3-12345",
+				Namespace: testNamespace,
+				Labels: map[string]string{
+					"k8s-app":    metal3AppName,
+					cboLabelName: stateService,
+				},
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "metal3-static-ip-set"},
+					{Name: "metal3-machine-os-downloader"},
+				},
+			},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: statuses,
+			},
+		}
+	}
+	tCases := []struct {
+		name          string
+		objects       []runtime.Object
+		expectedState InitializationState
+	}{
+		{
+			name:          "no pod",
+			expectedState: InitializationPodNotFound,
+		},
+		{
+			name: "downloading images",
+			objects: []runtime.Object{metal3Pod(
+				corev1.ContainerStatus{Name: "metal3-static-ip-set", State: terminated(0)},
+				corev1.ContainerStatus{Name: "metal3-machine-os-downloader", State: running},
+			)},
+			expectedState: InitializationInProgress,
+		},
+		{
+			name: "download failed",
+			objects: []runtime.Object{metal3Pod(
+				corev1.ContainerStatus{Name: "metal3-static-ip-set", State: terminated(0)},
+				corev1.ContainerStatus{Name: "metal3-machine-os-downloader", State: terminated(1)},
+			)},
+			expectedState: InitializationInProgress,
+		},
+		{
+			name: "complete",
+			objects: []runtime.Object{metal3Pod(
+				corev1.ContainerStatus{Name: "metal3-static-ip-set", State: terminated(0)},
+				corev1.ContainerStatus{Name: "metal3-machine-os-downloader", State: terminated(0)},
+			)},
+			expectedState: InitializationComplete,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Client:    fakekube.NewSimpleClientset(tc.objects...),
+				Namespace: testNamespace,
+			}
+			state, err := GetInitializationState(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedState, state)
+		})
+	}
+}
+
+func TestIsSingleNodeTopology(t *testing.T) {
+	infra := func(topology osconfigv1.TopologyMode) *osconfigv1.Infrastructure {
+		return &osconfigv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     osconfigv1.InfrastructureStatus{ControlPlaneTopology: topology},
+		}
+	}
+	tCases := []struct {
+		name     string
+		objects  []runtime.Object
+		expected bool
+	}{
+		{
+			name: "no infrastructure",
+		},
+		{
+			name:    "highly available",
+			objects: []runtime.Object{infra(osconfigv1.HighlyAvailableTopologyMode)},
+		},
+		{
+			name:     "single node",
+			objects:  []runtime.Object{infra(osconfigv1.SingleReplicaTopologyMode)},
+			expected: true,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			singleNode, err := IsSingleNodeTopology(fakeconfigclientset.NewSimpleClientset(tc.objects...))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, singleNode)
+		})
+	}
+}